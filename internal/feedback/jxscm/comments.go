@@ -0,0 +1,86 @@
+package jxscm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/pkg/errors"
+)
+
+// WalkPullRequestComments visits Pull Request comments until visit() returns true. The first page is checked first,
+// then the pages from the newest to the oldest when the SCM tells the number of pages, otherwise one by one.
+// Within a page the newest comments are visited first. Lists up to maxPages pages.
+// The walk expects comments listed oldest first, which is requested from SCMs that list them newest first by default
+func WalkPullRequestComments(ctx context.Context, service scm.PullRequestService, driver scm.Driver, repo string, number int,
+	maxPages int, visit func(comment *scm.Comment) bool) error {
+
+	comments, response, err := service.ListComments(ctx, repo, number, commentsListOptions(driver, 1))
+	if err != nil {
+		return errors.Wrap(err, "cannot list Pull Request comments")
+	}
+	if visitNewestFirst(comments, visit) {
+		return nil
+	}
+
+	// newest pages first, as the comments of the recent runs are at the end
+	if response != nil && response.Page.Last > 1 {
+		for page, fetched := response.Page.Last, 1; page > 1 && fetched < maxPages; page, fetched = page-1, fetched+1 {
+			comments, _, err = service.ListComments(ctx, repo, number, commentsListOptions(driver, page))
+			if err != nil {
+				return errors.Wrapf(err, "cannot list Pull Request comments, page %d", page)
+			}
+			if visitNewestFirst(comments, visit) {
+				return nil
+			}
+		}
+		return nil
+	}
+
+	// the SCM does not tell how many pages there are
+	page := nextPage(response, 1, len(comments))
+	for fetched := 1; page > 0 && fetched < maxPages; fetched++ {
+		comments, response, err = service.ListComments(ctx, repo, number, commentsListOptions(driver, page))
+		if err != nil {
+			return errors.Wrapf(err, "cannot list Pull Request comments, page %d", page)
+		}
+		if visitNewestFirst(comments, visit) {
+			return nil
+		}
+		page = nextPage(response, page, len(comments))
+	}
+	return nil
+}
+
+// FindPullRequestComment returns the newest comment containing the marking, nil when not found
+func FindPullRequestComment(ctx context.Context, service scm.PullRequestService, driver scm.Driver, repo string, number int,
+	marking string, maxPages int) (*scm.Comment, error) {
+
+	var found *scm.Comment
+	err := WalkPullRequestComments(ctx, service, driver, repo, number, maxPages, func(comment *scm.Comment) bool {
+		if strings.Contains(comment.Body, marking) {
+			found = comment
+			return true
+		}
+		return false
+	})
+	return found, err
+}
+
+// commentsListOptions requests the comments oldest first, GitLab lists notes newest first by default
+func commentsListOptions(driver scm.Driver, page int) *scm.ListOptions {
+	opts := &scm.ListOptions{Page: page, Size: pageSize}
+	if driver == scm.DriverGitlab {
+		opts.Sort = "asc"
+	}
+	return opts
+}
+
+func visitNewestFirst(comments []*scm.Comment, visit func(comment *scm.Comment) bool) bool {
+	for i := len(comments) - 1; i >= 0; i-- {
+		if visit(comments[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package jxscm_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// pagedComments is a Pull Request comments API with 100 comments per page, that optionally tells the number of pages
+type pagedComments struct {
+	scm.PullRequestService
+	comments     []*scm.Comment
+	withLastPage bool
+	failOnPage   int
+	fetchedPages []int
+
+	// newestFirst lists the comments newest first unless asked for "asc" sorting, like GitLab does
+	newestFirst bool
+}
+
+func (p *pagedComments) ListComments(ctx context.Context, repo string, number int, opts *scm.ListOptions) ([]*scm.Comment, *scm.Response, error) {
+	p.fetchedPages = append(p.fetchedPages, opts.Page)
+	if opts.Page == p.failOnPage {
+		return nil, nil, errors.New("502 Bad Gateway")
+	}
+	comments := p.comments
+	if p.newestFirst && opts.Sort != "asc" {
+		comments = make([]*scm.Comment, 0, len(p.comments))
+		for i := len(p.comments) - 1; i >= 0; i-- {
+			comments = append(comments, p.comments[i])
+		}
+	}
+	start := min((opts.Page-1)*opts.Size, len(comments))
+	end := min(start+opts.Size, len(comments))
+	response := &scm.Response{}
+	if p.withLastPage {
+		response.Page.Last = (len(comments) + opts.Size - 1) / opts.Size
+	}
+	return comments[start:end], response, nil
+}
+
+func createComments(count int) []*scm.Comment {
+	comments := make([]*scm.Comment, 0, count)
+	for i := 1; i <= count; i++ {
+		comments = append(comments, &scm.Comment{ID: i, Body: fmt.Sprintf("Comment #%d", i)})
+	}
+	return comments
+}
+
+func TestFindPullRequestComment_SearchesNewestPagesFirst(t *testing.T) {
+	service := &pagedComments{comments: createComments(450), withLastPage: true}
+	service.comments[320].Body = "Baking (pfc-id=team-1/bread/abc/updatePRStatusComment)"
+
+	found, err := jxscm.FindPullRequestComment(context.TODO(), service, scm.DriverGithub, "kube-cicd/bakery", 4, "(pfc-id=team-1/bread/abc/updatePRStatusComment)", 20)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 321, found.ID)
+	assert.Equal(t, []int{1, 5, 4}, service.fetchedPages, "should stop after the page with the comment was found")
+}
+
+func TestFindPullRequestComment_PaginatesWithoutKnownNumberOfPages(t *testing.T) {
+	service := &pagedComments{comments: createComments(250)}
+	service.comments[230].Body = "Baking (pfc-id=team-1/bread/abc/updatePRStatusComment)"
+
+	found, err := jxscm.FindPullRequestComment(context.TODO(), service, scm.DriverGithub, "kube-cicd/bakery", 4, "(pfc-id=team-1/bread/abc/updatePRStatusComment)", 20)
+	assert.Nil(t, err)
+	assert.Equal(t, 231, found.ID)
+	assert.Equal(t, []int{1, 2, 3}, service.fetchedPages)
+
+	limited, err := jxscm.FindPullRequestComment(context.TODO(), service, scm.DriverGithub, "kube-cicd/bakery", 4, "(pfc-id=team-1/bread/abc/updatePRStatusComment)", 2)
+	assert.Nil(t, err)
+	assert.Nil(t, limited, "Only two pages should be checked")
+}
+
+func TestFindPullRequestComment_ReturnsNewestMatch(t *testing.T) {
+	service := &pagedComments{comments: createComments(3)}
+	service.comments[0].Body = "(pfc-id=team-1/bread/abc/WhenFinished)"
+	service.comments[2].Body = "(pfc-id=team-1/bread/abc/WhenFinished)"
+
+	found, _ := jxscm.FindPullRequestComment(context.TODO(), service, scm.DriverGithub, "kube-cicd/bakery", 4, "(pfc-id=team-1/bread/abc/WhenFinished)", 20)
+
+	assert.Equal(t, 3, found.ID)
+}
+
+func TestFindPullRequestComment_ReturnsNewestMatchOnGitLab(t *testing.T) {
+	service := &pagedComments{comments: createComments(150), withLastPage: true, newestFirst: true}
+	service.comments[110].Body = "(pfc-id=team-1/bread/abc/WhenFinished)"
+	service.comments[140].Body = "(pfc-id=team-1/bread/abc/WhenFinished)"
+
+	found, err := jxscm.FindPullRequestComment(context.TODO(), service, scm.DriverGitlab, "kube-cicd/bakery", 4, "(pfc-id=team-1/bread/abc/WhenFinished)", 20)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 141, found.ID)
+	assert.Equal(t, []int{1, 2}, service.fetchedPages)
+}
+
+func TestFindPullRequestComment_ReturnsErrors(t *testing.T) {
+	service := &pagedComments{comments: createComments(450), withLastPage: true, failOnPage: 5}
+
+	found, err := jxscm.FindPullRequestComment(context.TODO(), service, scm.DriverGithub, "kube-cicd/bakery", 4, "(pfc-id=team-1/bread/abc/WhenFinished)", 20)
+
+	assert.Nil(t, found)
+	assert.Equal(t, "cannot list Pull Request comments, page 5: 502 Bad Gateway", err.Error())
+}
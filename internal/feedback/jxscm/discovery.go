@@ -0,0 +1,45 @@
+package jxscm
+
+import (
+	"context"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/pkg/errors"
+)
+
+const pageSize = 100
+
+// FindOpenPullRequestsByCommit is looking for open Pull/Merge requests that have the commit as their head.
+// Lists up to maxPages pages of open Pull Requests
+func FindOpenPullRequestsByCommit(ctx context.Context, service scm.PullRequestService, repo string, commit string, maxPages int) ([]int, error) {
+	found := make([]int, 0)
+	page := 1
+	for fetched := 0; fetched < maxPages; fetched++ {
+		prs, response, err := service.List(ctx, repo, &scm.PullRequestListOptions{Page: page, Size: pageSize, Open: true})
+		if err != nil {
+			return found, errors.Wrap(err, "cannot list Pull Requests")
+		}
+		for _, pr := range prs {
+			if pr.Head.Sha == commit || pr.Sha == commit {
+				found = append(found, pr.Number)
+			}
+		}
+		page = nextPage(response, page, len(prs))
+		if page == 0 {
+			break
+		}
+	}
+	return found, nil
+}
+
+// nextPage returns the next page number, or 0 when there are no more pages.
+// Some drivers do not return pagination links, then a full page means that there may be a next page
+func nextPage(response *scm.Response, current int, itemsCount int) int {
+	if response != nil && response.Page.Next > 0 {
+		return response.Page.Next
+	}
+	if itemsCount >= pageSize {
+		return current + 1
+	}
+	return 0
+}
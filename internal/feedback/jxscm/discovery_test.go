@@ -0,0 +1,58 @@
+package jxscm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/stretchr/testify/assert"
+)
+
+func createPullRequest(number int, sha string, closed bool) *scm.PullRequest {
+	return &scm.PullRequest{
+		Number: number,
+		Sha:    sha,
+		Closed: closed,
+		Head:   scm.PullRequestBranch{Sha: sha},
+		Base: scm.PullRequestBranch{
+			Repo: scm.Repository{Namespace: "anarchism", Name: "bread", FullName: "anarchism/bread"},
+		},
+	}
+}
+
+func TestFindOpenPullRequestsByCommit(t *testing.T) {
+	client, data := fake.NewDefault()
+	data.PullRequests = map[int]*scm.PullRequest{
+		1: createPullRequest(1, "76ea7c746d4e4ac42c44bf72946d3b0d399553dd", false),
+		2: createPullRequest(2, "2d6cc283fb5be9f963f2b70c504e4fedc6c025b8", false),
+		3: createPullRequest(3, "76ea7c746d4e4ac42c44bf72946d3b0d399553dd", true),
+		4: createPullRequest(4, "76ea7c746d4e4ac42c44bf72946d3b0d399553dd", false),
+	}
+
+	found, err := jxscm.FindOpenPullRequestsByCommit(context.TODO(), client.PullRequests, "anarchism/bread",
+		"76ea7c746d4e4ac42c44bf72946d3b0d399553dd", 5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 4}, found, "Closed Pull Requests and Pull Requests with other head should not be matched")
+}
+
+func TestFindOpenPullRequestsByCommit_Paginates(t *testing.T) {
+	client, data := fake.NewDefault()
+	data.PullRequests = map[int]*scm.PullRequest{}
+	for i := 1; i <= 250; i++ {
+		data.PullRequests[i] = createPullRequest(i, "2d6cc283fb5be9f963f2b70c504e4fedc6c025b8", false)
+	}
+	data.PullRequests[230] = createPullRequest(230, "76ea7c746d4e4ac42c44bf72946d3b0d399553dd", false)
+
+	found, err := jxscm.FindOpenPullRequestsByCommit(context.TODO(), client.PullRequests, "anarchism/bread",
+		"76ea7c746d4e4ac42c44bf72946d3b0d399553dd", 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{230}, found, "Pull Request on the third page should be found")
+
+	limited, err := jxscm.FindOpenPullRequestsByCommit(context.TODO(), client.PullRequests, "anarchism/bread",
+		"76ea7c746d4e4ac42c44bf72946d3b0d399553dd", 2)
+	assert.Nil(t, err)
+	assert.Empty(t, limited, "Only two pages should be checked")
+}
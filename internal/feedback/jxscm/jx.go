@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/azure"
@@ -68,7 +69,68 @@ func ensureGHEEndpoint(u string) string {
 	return u
 }
 
-func NewClientFromConfig(data config.Data, gitToken string) (*scm.Client, error) {
+// GuessDriverFromHost returns a go-scm driver name basing on well-known hosts and naming conventions
+// (e.g. gitlab.example.org), or an empty string when it cannot be guessed.
+// Only whole domain names are compared, so e.g. "notgithub.example.org" is not guessed
+func GuessDriverFromHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if hostname, _, found := strings.Cut(host, ":"); found {
+		host = hostname
+	}
+	switch {
+	case isDomainOrSubdomain(host, "github.com"):
+		return "github"
+	case isDomainOrSubdomain(host, "gitlab.com"):
+		return "gitlab"
+	case isDomainOrSubdomain(host, "bitbucket.org"):
+		return "bitbucketcloud"
+	case isDomainOrSubdomain(host, "dev.azure.com") || isDomainOrSubdomain(host, "visualstudio.com"):
+		return "azure"
+	case isDomainOrSubdomain(host, "gitea.com"):
+		return "gitea"
+	}
+
+	// self-hosted servers named by the convention e.g. "gitlab.example.org"
+	switch strings.Split(host, ".")[0] {
+	case "github":
+		return "github"
+	case "gitlab":
+		return "gitlab"
+	case "bitbucket":
+		return "stash"
+	case "gitea":
+		return "gitea"
+	}
+	return ""
+}
+
+// isDomainOrSubdomain checks that the host is the domain, or its subdomain
+func isDomainOrSubdomain(host string, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// GitHubApiUrl returns the API url of the GitHub server configured in "git-server", the same as used by the client
+func GitHubApiUrl(data config.Data) string {
+	serverURL := strings.TrimSpace(data.GetOrDefault("git-server", ""))
+	if serverURL == "" {
+		return "https://api.github.com"
+	}
+	if !strings.Contains(serverURL, "://") {
+		serverURL = "https://" + serverURL
+	}
+	return ensureGHEEndpoint(serverURL)
+}
+
+// NewClientFromConfig creates a client with its own HTTP transport. Use ClientPool to reuse clients and connections
+func NewClientFromConfig(data config.Data, gitToken string, opts TransportOptions) (*scm.Client, error) {
+	transport, err := NewTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newClientFromConfig(data, gitToken, transport)
+}
+
+func newClientFromConfig(data config.Data, gitToken string, base http.RoundTripper) (*scm.Client, error) {
 	if repoURL := data.GetOrDefault("git-repo-url", ""); repoURL != "" {
 		return factory.FromRepoURL(repoURL)
 	}
@@ -104,14 +166,22 @@ func NewClientFromConfig(data config.Data, gitToken string) (*scm.Client, error)
 	authOptions.clientID = clientID
 	authOptions.clientSecret = clientSecret
 
-	client, err := newClient(driver, serverURL, authOptions, setUsername(username))
+	timeout, timeoutErr := time.ParseDuration(data.GetOrDefault("timeout", "30s"))
+	if timeoutErr != nil {
+		return nil, errors.Wrap(timeoutErr, "invalid 'timeout'")
+	}
+
+	client, err := newClient(driver, serverURL, authOptions, base, setUsername(username))
+	if err == nil && client.Client != nil && client.Client != http.DefaultClient {
+		client.Client.Timeout = timeout
+	}
 	if driver == "" {
 		driver = client.Driver.String()
 	}
 	return client, err
 }
 
-func newClient(driver, serverURL string, authOptions *authOptions, opts ...ClientOptionFunc) (*scm.Client, error) {
+func newClient(driver, serverURL string, authOptions *authOptions, base http.RoundTripper, opts ...ClientOptionFunc) (*scm.Client, error) {
 	oauthToken := authOptions.oauthToken
 	if driver == "" {
 		driver = "github"
@@ -168,6 +238,7 @@ func newClient(driver, serverURL string, authOptions *authOptions, opts ...Clien
 		case "azure":
 			client.Client = &http.Client{
 				Transport: &transport.Custom{
+					Base: base,
 					Before: func(r *http.Request) {
 						encoded := base64.StdEncoding.EncodeToString([]byte(":" + oauthToken))
 						r.Header.Set("Authorization", fmt.Sprintf("Basic %s", encoded))
@@ -177,6 +248,7 @@ func newClient(driver, serverURL string, authOptions *authOptions, opts ...Clien
 		case "gitea":
 			client.Client = &http.Client{
 				Transport: &transport.Authorization{
+					Base:        base,
 					Scheme:      "token",
 					Credentials: oauthToken,
 				},
@@ -184,6 +256,7 @@ func newClient(driver, serverURL string, authOptions *authOptions, opts ...Clien
 		case "gitlab":
 			client.Client = &http.Client{
 				Transport: &transport.PrivateToken{
+					Base:  base,
 					Token: oauthToken,
 				},
 			}
@@ -201,12 +274,13 @@ func newClient(driver, serverURL string, authOptions *authOptions, opts ...Clien
 					ClientSecret: authOptions.clientSecret,
 					TokenURL:     "https://bitbucket.org/site/oauth2/access_token",
 				}
-				client.Client = config.Client(context.Background())
+				client.Client = config.Client(context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: base}))
 				return client, nil
 			}
 			// BB App Password / PAT
 			client.Client = &http.Client{
 				Transport: &transport.BasicAuth{
+					Base:     base,
 					Username: client.Username,
 					Password: oauthToken,
 				},
@@ -216,7 +290,7 @@ func newClient(driver, serverURL string, authOptions *authOptions, opts ...Clien
 			ts := oauth2.StaticTokenSource(
 				&oauth2.Token{AccessToken: oauthToken},
 			)
-			client.Client = oauth2.NewClient(context.Background(), ts)
+			client.Client = oauth2.NewClient(context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: base}), ts)
 		}
 	}
 	for _, o := range opts {
@@ -15,7 +15,7 @@ func TestNewClientFromConfig_InvalidGitServerURL(t *testing.T) {
 		"git-token":  "dummy-token",
 	}, &config.FakeValidator{}, nil)
 
-	_, err := jxscm.NewClientFromConfig(data, "")
+	_, err := jxscm.NewClientFromConfig(data, "", jxscm.TransportOptions{})
 	assert.NotNil(t, err)
 	assert.Equal(t, "invalid git-server URL: \"ht!tp://bad_url\". valid values are empty or a correctly formatted URL address", err.Error())
 }
@@ -27,7 +27,7 @@ func TestNewClientFromConfig_ValidGitServerURL(t *testing.T) {
 		"git-token":  "dummy-token",
 	}, &config.FakeValidator{}, nil)
 
-	client, err := jxscm.NewClientFromConfig(data, "")
+	client, err := jxscm.NewClientFromConfig(data, "", jxscm.TransportOptions{})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, client)
@@ -40,8 +40,34 @@ func TestNewClientFromConfig_EmptyGitServerURL(t *testing.T) {
 		"git-token":  "dummy-token",
 	}, &config.FakeValidator{}, nil)
 
-	client, err := jxscm.NewClientFromConfig(data, "")
+	client, err := jxscm.NewClientFromConfig(data, "", jxscm.TransportOptions{})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, client)
 }
+
+func TestGitHubApiUrl(t *testing.T) {
+	createData := func(server string) config.Data {
+		return config.NewData("jx-scm", map[string]string{"git-server": server}, &config.FakeValidator{}, nil)
+	}
+
+	assert.Equal(t, "https://api.github.com", jxscm.GitHubApiUrl(createData("")))
+	assert.Equal(t, "https://api.github.com", jxscm.GitHubApiUrl(createData("https://github.com")))
+	assert.Equal(t, "https://github.example.org/api/v3", jxscm.GitHubApiUrl(createData("github.example.org")))
+}
+
+func TestGuessDriverFromHost(t *testing.T) {
+	assert.Equal(t, "github", jxscm.GuessDriverFromHost("github.com"))
+	assert.Equal(t, "github", jxscm.GuessDriverFromHost("github.example.org"))
+	assert.Equal(t, "gitlab", jxscm.GuessDriverFromHost("GitLab.example.org:8443"))
+	assert.Equal(t, "bitbucketcloud", jxscm.GuessDriverFromHost("bitbucket.org"))
+	assert.Equal(t, "stash", jxscm.GuessDriverFromHost("bitbucket.example.org"))
+	assert.Equal(t, "", jxscm.GuessDriverFromHost("git.example.org"))
+	assert.Equal(t, "gitlab", jxscm.GuessDriverFromHost("gitlab.com"))
+	assert.Equal(t, "azure", jxscm.GuessDriverFromHost("bakery.visualstudio.com"))
+
+	// only whole domain names are compared
+	assert.Equal(t, "", jxscm.GuessDriverFromHost("notgithub.example.org"))
+	assert.Equal(t, "", jxscm.GuessDriverFromHost("scm.example.org-gitlab"))
+	assert.Equal(t, "", jxscm.GuessDriverFromHost("mybitbucket.org"))
+}
@@ -0,0 +1,26 @@
+package jxscm
+
+import (
+	"context"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/pkg/errors"
+)
+
+// ListPullRequestLabels returns all labels of the Pull Request. Lists up to maxPages pages
+func ListPullRequestLabels(ctx context.Context, service scm.PullRequestService, repo string, number int, maxPages int) ([]*scm.Label, error) {
+	found := make([]*scm.Label, 0)
+	page := 1
+	for fetched := 0; fetched < maxPages; fetched++ {
+		labels, response, err := service.ListLabels(ctx, repo, number, &scm.ListOptions{Page: page, Size: pageSize})
+		if err != nil {
+			return found, errors.Wrapf(err, "cannot list Pull Request labels, page %d", page)
+		}
+		found = append(found, labels...)
+		page = nextPage(response, page, len(labels))
+		if page == 0 {
+			break
+		}
+	}
+	return found, nil
+}
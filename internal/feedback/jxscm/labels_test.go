@@ -0,0 +1,42 @@
+package jxscm_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/stretchr/testify/assert"
+)
+
+// pagedLabels is a Pull Request labels API with 100 labels per page, without pagination links
+type pagedLabels struct {
+	scm.PullRequestService
+	labels       []*scm.Label
+	fetchedPages []int
+}
+
+func (p *pagedLabels) ListLabels(ctx context.Context, repo string, number int, opts *scm.ListOptions) ([]*scm.Label, *scm.Response, error) {
+	p.fetchedPages = append(p.fetchedPages, opts.Page)
+	start := min((opts.Page-1)*opts.Size, len(p.labels))
+	end := min(start+opts.Size, len(p.labels))
+	return p.labels[start:end], &scm.Response{}, nil
+}
+
+func TestListPullRequestLabels_Paginates(t *testing.T) {
+	service := &pagedLabels{}
+	for i := 1; i <= 230; i++ {
+		service.labels = append(service.labels, &scm.Label{Name: fmt.Sprintf("area/%d", i)})
+	}
+
+	labels, err := jxscm.ListPullRequestLabels(context.TODO(), service, "kube-cicd/bakery", 4, 10)
+	assert.Nil(t, err)
+	assert.Len(t, labels, 230)
+	assert.Equal(t, "area/230", labels[229].Name)
+	assert.Equal(t, []int{1, 2, 3}, service.fetchedPages)
+
+	limited, err := jxscm.ListPullRequestLabels(context.TODO(), service, "kube-cicd/bakery", 4, 2)
+	assert.Nil(t, err)
+	assert.Len(t, limited, 200, "Only two pages should be listed")
+}
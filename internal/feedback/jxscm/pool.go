@@ -0,0 +1,102 @@
+package jxscm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+)
+
+// DefaultMaxIdleTime is how long an unused client is kept in the pool
+const DefaultMaxIdleTime = 15 * time.Minute
+
+// clientKeys are all configuration keys used to construct a client
+var clientKeys = []string{
+	"git-repo-url",
+	"git-kind",
+	"git-server",
+	"git-token",
+	"git-user",
+	"bb-oauth-client-id",
+	"bb-oauth-client-secret",
+	"timeout",
+}
+
+type pooledClient struct {
+	client    *scm.Client
+	transport *http.Transport
+	lastUsed  time.Time
+}
+
+// ClientPool keeps SCM clients, so the HTTP connections are reused across reconciliations. A client is identified
+// by a hash of its configuration and credentials, so a changed `kind: PFConfig` or a rotated `kind: Secret`
+// results in a new client, while the old one is dropped after MaxIdleTime
+type ClientPool struct {
+	MaxIdleTime time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+}
+
+func NewClientPool(maxIdleTime time.Duration) *ClientPool {
+	return &ClientPool{
+		MaxIdleTime: maxIdleTime,
+		clients:     make(map[string]*pooledClient),
+	}
+}
+
+// Get returns a pooled client for given configuration, token and transport options, or creates a new one
+func (p *ClientPool) Get(data config.Data, gitToken string, opts TransportOptions) (*scm.Client, error) {
+	key := createClientKey(data, gitToken, opts)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.evictIdle()
+
+	if pooled, exists := p.clients[key]; exists {
+		pooled.lastUsed = time.Now()
+		return pooled.client, nil
+	}
+	transport, err := NewTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClientFromConfig(data, gitToken, transport)
+	if err != nil {
+		return client, err
+	}
+	p.clients[key] = &pooledClient{client: client, transport: transport, lastUsed: time.Now()}
+	return client, nil
+}
+
+// Len returns a number of pooled clients
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// evictIdle drops clients that were not used for MaxIdleTime and closes their connections
+func (p *ClientPool) evictIdle() {
+	deadline := time.Now().Add(-p.MaxIdleTime)
+	for key, pooled := range p.clients {
+		if pooled.lastUsed.Before(deadline) {
+			pooled.transport.CloseIdleConnections()
+			delete(p.clients, key)
+		}
+	}
+}
+
+func createClientKey(data config.Data, gitToken string, opts TransportOptions) string {
+	hash := sha256.New()
+	for _, key := range clientKeys {
+		hash.Write([]byte(key + "=" + data.Get(key) + "\n"))
+	}
+	hash.Write([]byte("token=" + gitToken + "\n"))
+	hash.Write([]byte("transport=" + opts.hash()))
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,67 @@
+package jxscm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func createData(kv map[string]string) config.Data {
+	return config.NewData("jx-scm", kv, &config.FakeValidator{}, nil)
+}
+
+func TestClientPool_ReusesClientForSameConfiguration(t *testing.T) {
+	pool := jxscm.NewClientPool(jxscm.DefaultMaxIdleTime)
+
+	first, err := pool.Get(createData(map[string]string{"git-kind": "gitlab", "timeout": "5s"}), "glpat-bakery", jxscm.TransportOptions{})
+	assert.Nil(t, err)
+	second, _ := pool.Get(createData(map[string]string{"git-kind": "gitlab", "timeout": "5s"}), "glpat-bakery", jxscm.TransportOptions{})
+
+	assert.Same(t, first, second)
+	assert.Equal(t, 5*time.Second, first.Client.Timeout)
+	assert.Equal(t, 1, pool.Len())
+}
+
+func TestClientPool_CreatesNewClientWhenCredentialsOrConfigurationChanged(t *testing.T) {
+	pool := jxscm.NewClientPool(jxscm.DefaultMaxIdleTime)
+
+	first, _ := pool.Get(createData(map[string]string{"git-kind": "gitlab"}), "glpat-bakery", jxscm.TransportOptions{})
+	rotated, _ := pool.Get(createData(map[string]string{"git-kind": "gitlab"}), "glpat-rotated", jxscm.TransportOptions{})
+	otherServer, _ := pool.Get(createData(map[string]string{"git-kind": "gitlab", "git-server": "gitlab.example.org"}), "glpat-rotated", jxscm.TransportOptions{})
+
+	assert.NotSame(t, first, rotated)
+	assert.NotSame(t, rotated, otherServer)
+	assert.Equal(t, 3, pool.Len())
+}
+
+func TestClientPool_EvictsIdleClients(t *testing.T) {
+	pool := jxscm.NewClientPool(time.Millisecond)
+
+	_, _ = pool.Get(createData(map[string]string{"git-kind": "gitlab"}), "glpat-bakery", jxscm.TransportOptions{})
+	time.Sleep(5 * time.Millisecond)
+	_, _ = pool.Get(createData(map[string]string{"git-kind": "gitlab"}), "glpat-rotated", jxscm.TransportOptions{})
+
+	assert.Equal(t, 1, pool.Len())
+}
+
+func TestClientPool_DoesNotKeepFailedClients(t *testing.T) {
+	pool := jxscm.NewClientPool(jxscm.DefaultMaxIdleTime)
+
+	_, err := pool.Get(createData(map[string]string{"git-kind": "gitlab", "timeout": "soon"}), "glpat-bakery", jxscm.TransportOptions{})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, pool.Len())
+}
+
+func TestClientPool_CreatesNewClientWhenTransportOptionsChanged(t *testing.T) {
+	pool := jxscm.NewClientPool(jxscm.DefaultMaxIdleTime)
+
+	direct, _ := pool.Get(createData(map[string]string{"git-kind": "gitlab"}), "glpat-bakery", jxscm.TransportOptions{})
+	proxied, _ := pool.Get(createData(map[string]string{"git-kind": "gitlab"}), "glpat-bakery", jxscm.TransportOptions{HttpsProxy: "http://proxy.example.org:3128"})
+
+	assert.NotSame(t, direct, proxied)
+	assert.Equal(t, 2, pool.Len())
+}
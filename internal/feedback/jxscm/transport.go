@@ -0,0 +1,83 @@
+package jxscm
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TransportOptions configure TLS and proxy of the connection to the SCM server
+type TransportOptions struct {
+	// CaBundle is a PEM encoded list of certificates trusted in addition to the system ones
+	CaBundle           string
+	InsecureSkipVerify bool
+
+	// ClientCert and ClientKey are PEM encoded, used for mTLS
+	ClientCert string
+	ClientKey  string
+
+	// HttpProxy, HttpsProxy and NoProxy override HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	HttpProxy  string
+	HttpsProxy string
+	NoProxy    string
+}
+
+// hash identifies the options, so the pooled client is replaced when e.g. a certificate was rotated
+func (o TransportOptions) hash() string {
+	sum := sha256.Sum256([]byte(o.CaBundle + "\n" + strconv.FormatBool(o.InsecureSkipVerify) + "\n" + o.ClientCert + "\n" +
+		o.ClientKey + "\n" + o.HttpProxy + "\n" + o.HttpsProxy + "\n" + o.NoProxy))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewTransport creates a keep-alive transport, a base for the authorization transports of all drivers
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 10
+
+	// InsecureSkipVerify is an escape hatch explicitly requested by the user
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CaBundle != "" {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM([]byte(opts.CaBundle)) {
+			return nil, errors.New("CA bundle does not contain any valid PEM certificate")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if opts.ClientCert != "" || opts.ClientKey != "" {
+		certificate, err := tls.X509KeyPair([]byte(opts.ClientCert), []byte(opts.ClientKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot load client certificate and key")
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if opts.HttpProxy != "" || opts.HttpsProxy != "" || opts.NoProxy != "" {
+		// only the specified options replace the environment variables, the rest is still taken from the environment
+		proxyConfig := httpproxy.FromEnvironment()
+		if opts.HttpProxy != "" {
+			proxyConfig.HTTPProxy = opts.HttpProxy
+		}
+		if opts.HttpsProxy != "" {
+			proxyConfig.HTTPSProxy = opts.HttpsProxy
+		}
+		if opts.NoProxy != "" {
+			proxyConfig.NoProxy = opts.NoProxy
+		}
+		proxyFunc := proxyConfig.ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+	return transport, nil
+}
@@ -0,0 +1,86 @@
+package jxscm_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransport_TrustsCaBundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	// without the CA bundle the internal CA is not trusted
+	untrusted, _ := jxscm.NewTransport(jxscm.TransportOptions{})
+	_, err := (&http.Client{Transport: untrusted}).Get(server.URL)
+	assert.NotNil(t, err)
+
+	trusted, err := jxscm.NewTransport(jxscm.TransportOptions{CaBundle: caBundle})
+	assert.Nil(t, err)
+	response, err := (&http.Client{Transport: trusted}).Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
+
+func TestNewTransport_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	transport, _ := jxscm.NewTransport(jxscm.TransportOptions{InsecureSkipVerify: true})
+	response, err := (&http.Client{Transport: transport}).Get(server.URL)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
+
+func TestNewTransport_RejectsInvalidCertificates(t *testing.T) {
+	_, caErr := jxscm.NewTransport(jxscm.TransportOptions{CaBundle: "this is not a certificate"})
+	_, mtlsErr := jxscm.NewTransport(jxscm.TransportOptions{ClientCert: "not a cert", ClientKey: "not a key"})
+
+	assert.Equal(t, "CA bundle does not contain any valid PEM certificate", caErr.Error())
+	assert.Contains(t, mtlsErr.Error(), "cannot load client certificate and key")
+}
+
+func TestNewTransport_ProxyOverrides(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "")
+	transport, _ := jxscm.NewTransport(jxscm.TransportOptions{
+		HttpsProxy: "http://proxy.example.org:3128",
+		NoProxy:    "gitlab.internal",
+	})
+
+	external, _ := http.NewRequest(http.MethodGet, "https://gitlab.com/api/v4/projects", nil)
+	internal, _ := http.NewRequest(http.MethodGet, "https://gitlab.internal/api/v4/projects", nil)
+	externalProxy, _ := transport.Proxy(external)
+	internalProxy, _ := transport.Proxy(internal)
+
+	assert.Equal(t, "http://proxy.example.org:3128", externalProxy.String())
+	assert.Nil(t, internalProxy)
+}
+
+func TestNewTransport_ProxyOverridesKeepOtherEnvironmentVariables(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://env-proxy.example.org:3128")
+	t.Setenv("HTTPS_PROXY", "http://env-proxy.example.org:3128")
+	t.Setenv("NO_PROXY", "gitlab.internal")
+	transport, _ := jxscm.NewTransport(jxscm.TransportOptions{HttpsProxy: "http://proxy.example.org:3128"})
+
+	external, _ := http.NewRequest(http.MethodGet, "https://gitlab.com/api/v4/projects", nil)
+	plain, _ := http.NewRequest(http.MethodGet, "http://gitlab.com/api/v4/projects", nil)
+	internal, _ := http.NewRequest(http.MethodGet, "https://gitlab.internal/api/v4/projects", nil)
+	externalProxy, _ := transport.Proxy(external)
+	plainProxy, _ := transport.Proxy(plain)
+	internalProxy, _ := transport.Proxy(internal)
+
+	assert.Equal(t, "http://proxy.example.org:3128", externalProxy.String())
+	assert.Equal(t, "http://env-proxy.example.org:3128", plainProxy.String())
+	assert.Nil(t, internalProxy, "NO_PROXY from the environment is respected")
+}
@@ -1,4 +1,4 @@
-// Package v1alpha1 contains PFConfig
+// Package v1alpha1 contains PFConfig and PipelineFeedbackRecord
 // +k8s:openapi-gen=true
 // +k8s:deepcopy-gen=package,register
 // +k8s:conversion-gen=github.com/kube-cicd/pipelines-feedback-core/pkg/apis/pipelinesfeedback.keskad.pl
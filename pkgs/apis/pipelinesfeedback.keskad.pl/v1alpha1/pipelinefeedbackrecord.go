@@ -0,0 +1,94 @@
+package v1alpha1
+
+import (
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=pfr
+// +kubebuilder:printcolumn:name="Pipeline",type=string,JSONPath=`.spec.pipeline.name`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.spec.status`
+// +kubebuilder:printcolumn:name="Commit",type=string,JSONPath=`.spec.scm.commit`
+// +kubebuilder:printcolumn:name="PR",type=string,JSONPath=`.spec.scm.prId`
+// +kubebuilder:printcolumn:name="Finished",type=date,JSONPath=`.spec.finishedAt`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PipelineFeedbackRecord is an audit trail entry of a single Pipeline execution. It is not owned by the Pipeline,
+// so it is kept after the Pipeline was garbage-collected
+type PipelineFeedbackRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RecordSpec `json:"spec"`
+}
+
+// RecordSpec represents .spec
+type RecordSpec struct {
+	Pipeline     RecordPipeline  `json:"pipeline"`
+	Status       contract.Status `json:"status"`
+	Description  string          `json:"description,omitempty"`
+	DashboardUrl string          `json:"dashboardUrl,omitempty"`
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// +optional
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+	// +optional
+	Stages []RecordStage `json:"stages,omitempty"`
+	Scm    RecordScm     `json:"scm"`
+	// Deliveries are references to comments, statuses and messages sent by the Feedback Receiver
+	// +optional
+	Deliveries []RecordDelivery `json:"deliveries,omitempty"`
+}
+
+// RecordPipeline represents .spec.pipeline
+type RecordPipeline struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	InstanceName string `json:"instanceName"`
+}
+
+// RecordStage represents an item of .spec.stages
+type RecordStage struct {
+	Name   string          `json:"name"`
+	Status contract.Status `json:"status"`
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// +optional
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+}
+
+// RecordScm represents .spec.scm
+type RecordScm struct {
+	Repository   string `json:"repository,omitempty"`
+	Commit       string `json:"commit,omitempty"`
+	Reference    string `json:"reference,omitempty"`
+	PrId         string `json:"prId,omitempty"`
+	SourceBranch string `json:"sourceBranch,omitempty"`
+	TargetBranch string `json:"targetBranch,omitempty"`
+	Tag          string `json:"tag,omitempty"`
+	TechnicalJob string `json:"technicalJob,omitempty"`
+}
+
+// RecordDelivery represents an item of .spec.deliveries
+type RecordDelivery struct {
+	// Receiver is a name of the Feedback Receiver e.g. "jxscm"
+	Receiver string `json:"receiver"`
+	// Kind describes what was delivered e.g. "comment" or "message"
+	Kind string `json:"kind"`
+	// Id is an identifier in the target system e.g. a comment id
+	Id string `json:"id"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// PipelineFeedbackRecordList represents a list
+type PipelineFeedbackRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PipelineFeedbackRecord `json:"items"`
+}
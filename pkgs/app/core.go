@@ -1,15 +1,27 @@
 package app
 
 import (
+	"context"
 	"os"
 	"strings"
 
 	pipelinesfeedbackv1alpha1scheme "github.com/kube-cicd/pipelines-feedback-core/pkgs/client/clientset/versioned/scheme"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/controller"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/cdevents"
 	debugFeedback "github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/debug"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/email"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/githubchecks"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/history"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/jxscm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/messagebus"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/msteams"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/opentelemetry"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/slack"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/webhook"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
 	"github.com/pkg/errors"
@@ -22,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
@@ -46,6 +59,13 @@ type PipelinesFeedbackApp struct {
 	LeaderElectId          string
 	RestrictNamespaces     string
 
+	// annotation and label names conventions of this controller instance
+	AnnotationBase       string
+	EnabledLabelSelector string
+
+	// wraps the FeedbackReceiver, so each Pipeline execution is recorded as a `kind: PipelineFeedbackRecord`
+	RecordHistory bool
+
 	CustomFeedbackReceiver string
 	CustomStore            string
 	CustomConfigCollector  string
@@ -77,12 +97,16 @@ func (app *PipelinesFeedbackApp) Run() error {
 	if err := app.populateFeedbackReceiver(); err != nil {
 		return err
 	}
+	app.populateHistoryRecording()
 	if err := app.populateConfigCollector(); err != nil {
 		return err
 	}
 	if err := app.populateStoreAdapter(); err != nil {
 		return err
 	}
+	if err := app.populateConventions(); err != nil {
+		return err
+	}
 
 	// add a standard scheme and Pipelines Feedback Core CRDs
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
@@ -145,6 +169,7 @@ func (app *PipelinesFeedbackApp) Run() error {
 			"logs-max-line-length",
 			"logs-max-full-length-lines-count",
 			"logs-split-separator",
+			"owner-references-max-depth",
 		},
 	})
 
@@ -152,6 +177,8 @@ func (app *PipelinesFeedbackApp) Run() error {
 	if err := app.ConfigController.Initialize(kubeconfig, app.ConfigCollector, app.Logger, app.JobController.Store, app.schema); err != nil {
 		return errors.Wrap(err, "cannot push dependencies to ConfigurationController")
 	}
+	// reads directly from the API, a cached client would start cluster-wide informers for each kind read
+	app.JobController.KubeClient = mgr.GetAPIReader()
 	if err := app.JobController.InjectDependencies(recorder, kubeconfig, app.Logger,
 		app.ConfigController.Provider, app.schema); err != nil {
 
@@ -175,6 +202,17 @@ func (app *PipelinesFeedbackApp) Run() error {
 		}
 	}
 
+	// release e.g. connections kept open by the FeedbackReceiver, when the manager stops
+	if closer, ok := app.JobController.FeedbackReceiver.(wiring.WithClosing); ok {
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			closer.Close()
+			return nil
+		})); err != nil {
+			return errors.Wrap(err, "cannot register closing of the FeedbackReceiver")
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		app.Logger.Error(err, "unable to set up healthz")
 		return err
@@ -202,6 +240,15 @@ func (app *PipelinesFeedbackApp) populateFeedbackReceiver() error {
 	if app.AvailableFeedbackReceivers == nil {
 		app.AvailableFeedbackReceivers = []feedback.Receiver{
 			&jxscm.Receiver{},
+			&githubchecks.Receiver{},
+			&webhook.Receiver{},
+			&cdevents.Receiver{},
+			&slack.Receiver{},
+			&msteams.Receiver{},
+			&email.Receiver{},
+			&opentelemetry.Receiver{},
+			&messagebus.Receiver{},
+			&history.Receiver{},
 			&debugFeedback.Receiver{},
 		}
 	}
@@ -214,6 +261,17 @@ func (app *PipelinesFeedbackApp) populateFeedbackReceiver() error {
 	return errors.New("unrecognized FeedbackProvider")
 }
 
+// populateHistoryRecording wraps the selected FeedbackReceiver with a Receiver that writes `kind: PipelineFeedbackRecord`
+func (app *PipelinesFeedbackApp) populateHistoryRecording() {
+	if !app.RecordHistory || app.JobController.FeedbackReceiver == nil {
+		return
+	}
+	if _, isHistory := app.JobController.FeedbackReceiver.(*history.Receiver); isHistory {
+		return
+	}
+	app.JobController.FeedbackReceiver = &history.Receiver{Delegate: app.JobController.FeedbackReceiver}
+}
+
 func (app *PipelinesFeedbackApp) populateConfigCollector() error {
 	// if the user did not select anything
 	if app.CustomConfigCollector == "" {
@@ -241,6 +299,15 @@ func (app *PipelinesFeedbackApp) populateConfigCollector() error {
 	return nil
 }
 
+func (app *PipelinesFeedbackApp) populateConventions() error {
+	conventions, err := contract.NewConventions(app.AnnotationBase, app.EnabledLabelSelector)
+	if err != nil {
+		return errors.Wrap(err, "cannot configure annotations and labels")
+	}
+	app.JobController.Conventions = conventions
+	return nil
+}
+
 func (app *PipelinesFeedbackApp) populateStoreAdapter() error {
 	if app.CustomStore == "" {
 		return nil
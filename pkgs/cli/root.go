@@ -2,6 +2,7 @@ package cli
 
 import (
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/app"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
 	"github.com/spf13/cobra"
 )
 
@@ -39,6 +40,9 @@ func NewRootCommand(app *app.PipelinesFeedbackApp) *cobra.Command {
 	command.Flags().BoolVarP(&app.LeaderElect, "leader-elect", "l", false, "Enable leader election")
 	command.Flags().StringVarP(&app.ControllerName, "controller-name", "", "pipelines-feedback", "Controller name - useful when running multiple controllers on the same cluster")
 	command.Flags().StringVarP(&app.LeaderElectId, "instance-id", "", "aSaMKO0", "Leader election ID (should not be changed, unless you know what you are doing)")
+	command.Flags().StringVarP(&app.AnnotationBase, "annotation-base", "", "", "Annotations prefix e.g. 'pipelinesfeedback.keskad.pl' in 'pipelinesfeedback.keskad.pl/commit' (default \""+contract.DefaultAnnotationBase+"\", or ANNOTATION_FEEDBACK_BASE env)")
+	command.Flags().StringVarP(&app.EnabledLabelSelector, "enabled-label-selector", "", "", "Label selector expression that decides which objects are handled by the controller (default \""+contract.DefaultEnabledLabelSelector+"\", or LABEL_FEEDBACK_ENABLED_NAME and LABEL_FEEDBACK_ENABLED_VALUE env)")
+	command.Flags().BoolVarP(&app.RecordHistory, "record-history", "", false, "Record each Pipeline execution as a 'kind: PipelineFeedbackRecord' in addition to sending the feedback")
 
 	// error handling
 	command.Flags().IntVarP(&app.DelayAfterErrorNum, "requeue-delay-after-error-count", "", 100, "Delay reconciliation of this resource, after it failed X times")
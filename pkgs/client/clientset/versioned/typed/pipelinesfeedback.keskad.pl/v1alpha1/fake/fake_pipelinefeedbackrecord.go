@@ -0,0 +1,129 @@
+/*
+Copyright Damian Kęska.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/kube-cicd/pipelines-feedback-core/pkgs/apis/pipelinesfeedback.keskad.pl/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakePipelineFeedbackRecords implements PipelineFeedbackRecordInterface
+type FakePipelineFeedbackRecords struct {
+	Fake *FakePipelinesfeedbackV1alpha1
+	ns   string
+}
+
+var pipelinefeedbackrecordsResource = v1alpha1.SchemeGroupVersion.WithResource("pipelinefeedbackrecords")
+
+var pipelinefeedbackrecordsKind = v1alpha1.SchemeGroupVersion.WithKind("PipelineFeedbackRecord")
+
+// Get takes name of the pipelineFeedbackRecord, and returns the corresponding pipelineFeedbackRecord object, and an error if there is any.
+func (c *FakePipelineFeedbackRecords) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.PipelineFeedbackRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(pipelinefeedbackrecordsResource, c.ns, name), &v1alpha1.PipelineFeedbackRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PipelineFeedbackRecord), err
+}
+
+// List takes label and field selectors, and returns the list of PipelineFeedbackRecords that match those selectors.
+func (c *FakePipelineFeedbackRecords) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.PipelineFeedbackRecordList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(pipelinefeedbackrecordsResource, pipelinefeedbackrecordsKind, c.ns, opts), &v1alpha1.PipelineFeedbackRecordList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.PipelineFeedbackRecordList{ListMeta: obj.(*v1alpha1.PipelineFeedbackRecordList).ListMeta}
+	for _, item := range obj.(*v1alpha1.PipelineFeedbackRecordList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested pipelineFeedbackRecords.
+func (c *FakePipelineFeedbackRecords) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(pipelinefeedbackrecordsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a pipelineFeedbackRecord and creates it.  Returns the server's representation of the pipelineFeedbackRecord, and an error, if there is any.
+func (c *FakePipelineFeedbackRecords) Create(ctx context.Context, pipelineFeedbackRecord *v1alpha1.PipelineFeedbackRecord, opts v1.CreateOptions) (result *v1alpha1.PipelineFeedbackRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(pipelinefeedbackrecordsResource, c.ns, pipelineFeedbackRecord), &v1alpha1.PipelineFeedbackRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PipelineFeedbackRecord), err
+}
+
+// Update takes the representation of a pipelineFeedbackRecord and updates it. Returns the server's representation of the pipelineFeedbackRecord, and an error, if there is any.
+func (c *FakePipelineFeedbackRecords) Update(ctx context.Context, pipelineFeedbackRecord *v1alpha1.PipelineFeedbackRecord, opts v1.UpdateOptions) (result *v1alpha1.PipelineFeedbackRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(pipelinefeedbackrecordsResource, c.ns, pipelineFeedbackRecord), &v1alpha1.PipelineFeedbackRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PipelineFeedbackRecord), err
+}
+
+// Delete takes name of the pipelineFeedbackRecord and deletes it. Returns an error if one occurs.
+func (c *FakePipelineFeedbackRecords) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(pipelinefeedbackrecordsResource, c.ns, name, opts), &v1alpha1.PipelineFeedbackRecord{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakePipelineFeedbackRecords) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(pipelinefeedbackrecordsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.PipelineFeedbackRecordList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched pipelineFeedbackRecord.
+func (c *FakePipelineFeedbackRecords) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PipelineFeedbackRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(pipelinefeedbackrecordsResource, c.ns, name, pt, data, subresources...), &v1alpha1.PipelineFeedbackRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PipelineFeedbackRecord), err
+}
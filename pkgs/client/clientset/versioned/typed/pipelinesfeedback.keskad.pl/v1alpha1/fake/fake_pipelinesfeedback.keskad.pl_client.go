@@ -32,6 +32,10 @@ func (c *FakePipelinesfeedbackV1alpha1) PFConfigs(namespace string) v1alpha1.PFC
 	return &FakePFConfigs{c, namespace}
 }
 
+func (c *FakePipelinesfeedbackV1alpha1) PipelineFeedbackRecords(namespace string) v1alpha1.PipelineFeedbackRecordInterface {
+	return &FakePipelineFeedbackRecords{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakePipelinesfeedbackV1alpha1) RESTClient() rest.Interface {
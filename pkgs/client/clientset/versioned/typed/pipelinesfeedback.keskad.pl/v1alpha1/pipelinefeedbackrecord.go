@@ -0,0 +1,178 @@
+/*
+Copyright Damian Kęska.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/kube-cicd/pipelines-feedback-core/pkgs/apis/pipelinesfeedback.keskad.pl/v1alpha1"
+	scheme "github.com/kube-cicd/pipelines-feedback-core/pkgs/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// PipelineFeedbackRecordsGetter has a method to return a PipelineFeedbackRecordInterface.
+// A group's client should implement this interface.
+type PipelineFeedbackRecordsGetter interface {
+	PipelineFeedbackRecords(namespace string) PipelineFeedbackRecordInterface
+}
+
+// PipelineFeedbackRecordInterface has methods to work with PipelineFeedbackRecord resources.
+type PipelineFeedbackRecordInterface interface {
+	Create(ctx context.Context, pipelineFeedbackRecord *v1alpha1.PipelineFeedbackRecord, opts v1.CreateOptions) (*v1alpha1.PipelineFeedbackRecord, error)
+	Update(ctx context.Context, pipelineFeedbackRecord *v1alpha1.PipelineFeedbackRecord, opts v1.UpdateOptions) (*v1alpha1.PipelineFeedbackRecord, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.PipelineFeedbackRecord, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.PipelineFeedbackRecordList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PipelineFeedbackRecord, err error)
+	PipelineFeedbackRecordExpansion
+}
+
+// pipelineFeedbackRecords implements PipelineFeedbackRecordInterface
+type pipelineFeedbackRecords struct {
+	client rest.Interface
+	ns     string
+}
+
+// newPipelineFeedbackRecords returns a PipelineFeedbackRecords
+func newPipelineFeedbackRecords(c *PipelinesfeedbackV1alpha1Client, namespace string) *pipelineFeedbackRecords {
+	return &pipelineFeedbackRecords{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the pipelineFeedbackRecord, and returns the corresponding pipelineFeedbackRecord object, and an error if there is any.
+func (c *pipelineFeedbackRecords) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.PipelineFeedbackRecord, err error) {
+	result = &v1alpha1.PipelineFeedbackRecord{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("pipelinefeedbackrecords").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of PipelineFeedbackRecords that match those selectors.
+func (c *pipelineFeedbackRecords) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.PipelineFeedbackRecordList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.PipelineFeedbackRecordList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("pipelinefeedbackrecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested pipelineFeedbackRecords.
+func (c *pipelineFeedbackRecords) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("pipelinefeedbackrecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a pipelineFeedbackRecord and creates it.  Returns the server's representation of the pipelineFeedbackRecord, and an error, if there is any.
+func (c *pipelineFeedbackRecords) Create(ctx context.Context, pipelineFeedbackRecord *v1alpha1.PipelineFeedbackRecord, opts v1.CreateOptions) (result *v1alpha1.PipelineFeedbackRecord, err error) {
+	result = &v1alpha1.PipelineFeedbackRecord{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("pipelinefeedbackrecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(pipelineFeedbackRecord).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a pipelineFeedbackRecord and updates it. Returns the server's representation of the pipelineFeedbackRecord, and an error, if there is any.
+func (c *pipelineFeedbackRecords) Update(ctx context.Context, pipelineFeedbackRecord *v1alpha1.PipelineFeedbackRecord, opts v1.UpdateOptions) (result *v1alpha1.PipelineFeedbackRecord, err error) {
+	result = &v1alpha1.PipelineFeedbackRecord{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("pipelinefeedbackrecords").
+		Name(pipelineFeedbackRecord.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(pipelineFeedbackRecord).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the pipelineFeedbackRecord and deletes it. Returns an error if one occurs.
+func (c *pipelineFeedbackRecords) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("pipelinefeedbackrecords").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *pipelineFeedbackRecords) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("pipelinefeedbackrecords").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched pipelineFeedbackRecord.
+func (c *pipelineFeedbackRecords) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PipelineFeedbackRecord, err error) {
+	result = &v1alpha1.PipelineFeedbackRecord{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("pipelinefeedbackrecords").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -29,6 +29,7 @@ import (
 type PipelinesfeedbackV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	PFConfigsGetter
+	PipelineFeedbackRecordsGetter
 }
 
 // PipelinesfeedbackV1alpha1Client is used to interact with features provided by the pipelinesfeedback.keskad.pl group.
@@ -40,6 +41,10 @@ func (c *PipelinesfeedbackV1alpha1Client) PFConfigs(namespace string) PFConfigIn
 	return newPFConfigs(c, namespace)
 }
 
+func (c *PipelinesfeedbackV1alpha1Client) PipelineFeedbackRecords(namespace string) PipelineFeedbackRecordInterface {
+	return newPipelineFeedbackRecords(c, namespace)
+}
+
 // NewForConfig creates a new PipelinesfeedbackV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
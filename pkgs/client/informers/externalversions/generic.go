@@ -55,6 +55,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=pipelinesfeedback.keskad.pl, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("pfconfigs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Pipelinesfeedback().V1alpha1().PFConfigs().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("pipelinefeedbackrecords"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Pipelinesfeedback().V1alpha1().PipelineFeedbackRecords().Informer()}, nil
 
 	}
 
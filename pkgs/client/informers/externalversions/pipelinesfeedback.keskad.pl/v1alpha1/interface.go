@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// PFConfigs returns a PFConfigInformer.
 	PFConfigs() PFConfigInformer
+	// PipelineFeedbackRecords returns a PipelineFeedbackRecordInformer.
+	PipelineFeedbackRecords() PipelineFeedbackRecordInformer
 }
 
 type version struct {
@@ -43,3 +45,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) PFConfigs() PFConfigInformer {
 	return &pFConfigInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// PipelineFeedbackRecords returns a PipelineFeedbackRecordInformer.
+func (v *version) PipelineFeedbackRecords() PipelineFeedbackRecordInformer {
+	return &pipelineFeedbackRecordInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -25,3 +25,11 @@ type PFConfigListerExpansion interface{}
 // PFConfigNamespaceListerExpansion allows custom methods to be added to
 // PFConfigNamespaceLister.
 type PFConfigNamespaceListerExpansion interface{}
+
+// PipelineFeedbackRecordListerExpansion allows custom methods to be added to
+// PipelineFeedbackRecordLister.
+type PipelineFeedbackRecordListerExpansion interface{}
+
+// PipelineFeedbackRecordNamespaceListerExpansion allows custom methods to be added to
+// PipelineFeedbackRecordNamespaceLister.
+type PipelineFeedbackRecordNamespaceListerExpansion interface{}
@@ -0,0 +1,99 @@
+/*
+Copyright Damian Kęska.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/kube-cicd/pipelines-feedback-core/pkgs/apis/pipelinesfeedback.keskad.pl/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PipelineFeedbackRecordLister helps list PipelineFeedbackRecords.
+// All objects returned here must be treated as read-only.
+type PipelineFeedbackRecordLister interface {
+	// List lists all PipelineFeedbackRecords in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.PipelineFeedbackRecord, err error)
+	// PipelineFeedbackRecords returns an object that can list and get PipelineFeedbackRecords.
+	PipelineFeedbackRecords(namespace string) PipelineFeedbackRecordNamespaceLister
+	PipelineFeedbackRecordListerExpansion
+}
+
+// pipelineFeedbackRecordLister implements the PipelineFeedbackRecordLister interface.
+type pipelineFeedbackRecordLister struct {
+	indexer cache.Indexer
+}
+
+// NewPipelineFeedbackRecordLister returns a new PipelineFeedbackRecordLister.
+func NewPipelineFeedbackRecordLister(indexer cache.Indexer) PipelineFeedbackRecordLister {
+	return &pipelineFeedbackRecordLister{indexer: indexer}
+}
+
+// List lists all PipelineFeedbackRecords in the indexer.
+func (s *pipelineFeedbackRecordLister) List(selector labels.Selector) (ret []*v1alpha1.PipelineFeedbackRecord, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.PipelineFeedbackRecord))
+	})
+	return ret, err
+}
+
+// PipelineFeedbackRecords returns an object that can list and get PipelineFeedbackRecords.
+func (s *pipelineFeedbackRecordLister) PipelineFeedbackRecords(namespace string) PipelineFeedbackRecordNamespaceLister {
+	return pipelineFeedbackRecordNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// PipelineFeedbackRecordNamespaceLister helps list and get PipelineFeedbackRecords.
+// All objects returned here must be treated as read-only.
+type PipelineFeedbackRecordNamespaceLister interface {
+	// List lists all PipelineFeedbackRecords in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.PipelineFeedbackRecord, err error)
+	// Get retrieves the PipelineFeedbackRecord from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.PipelineFeedbackRecord, error)
+	PipelineFeedbackRecordNamespaceListerExpansion
+}
+
+// pipelineFeedbackRecordNamespaceLister implements the PipelineFeedbackRecordNamespaceLister
+// interface.
+type pipelineFeedbackRecordNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all PipelineFeedbackRecords in the indexer for a given namespace.
+func (s pipelineFeedbackRecordNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.PipelineFeedbackRecord, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.PipelineFeedbackRecord))
+	})
+	return ret, err
+}
+
+// Get retrieves the PipelineFeedbackRecord from the indexer for a given namespace and name.
+func (s pipelineFeedbackRecordNamespaceLister) Get(name string) (*v1alpha1.PipelineFeedbackRecord, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("pipelinefeedbackrecord"), name)
+	}
+	return obj.(*v1alpha1.PipelineFeedbackRecord), nil
+}
@@ -45,3 +45,24 @@ func (d *Data) GetOrDefault(keyName string, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+// WithOverrides returns a copy of the Data with given values replaced. Keys listed in `reset` are not inherited,
+// so e.g. credentials of one server are not mixed with credentials of other
+func (d *Data) WithOverrides(values map[string]string, reset []string) (Data, error) {
+	for key := range values {
+		if err := d.validator.ValidateRequestedEntry(d.component, key); err != nil {
+			return Data{}, err
+		}
+	}
+	kv := make(map[string]string, len(d.kv)+len(values))
+	for key, value := range d.kv {
+		kv[key] = value
+	}
+	for _, key := range reset {
+		delete(kv, key)
+	}
+	for key, value := range values {
+		kv[key] = value
+	}
+	return NewData(d.component, kv, d.validator, d.logger), nil
+}
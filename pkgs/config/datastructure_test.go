@@ -34,3 +34,29 @@ type FakeLogger struct {
 func (l *FakeLogger) Fatalf(format string, args ...interface{}) {
 	l.Called = true
 }
+
+func TestData_WithOverrides(t *testing.T) {
+	cfg := config.NewData("jxscm", map[string]string{
+		"token":    "ghp_bakery",
+		"git-kind": "github",
+		"hello":    "bread",
+	}, &fake.NullValidator{}, &logging.InternalLogger{})
+
+	overridden, err := cfg.WithOverrides(map[string]string{"git-kind": "gitlab"}, []string{"token", "git-kind"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "gitlab", overridden.Get("git-kind"))
+	assert.Equal(t, "bread", overridden.Get("hello"))
+	assert.False(t, overridden.HasKey("token"))
+	assert.Equal(t, "ghp_bakery", cfg.Get("token"), "original Data should stay untouched")
+}
+
+func TestData_WithOverrides_RejectsUnknownKeys(t *testing.T) {
+	validator := &config.SchemaValidator{}
+	validator.Add(config.Schema{Name: "jxscm", AllowedFields: []string{"git-kind"}})
+	cfg := config.NewData("jxscm", map[string]string{}, validator, &logging.InternalLogger{})
+
+	_, err := cfg.WithOverrides(map[string]string{"gti-kind": "gitlab"}, []string{})
+
+	assert.Equal(t, "field 'gti-kind' is not a valid field for component 'jxscm'", err.Error())
+}
@@ -18,6 +18,7 @@ type ConfigurationProviderInterface interface {
 	FetchContextual(component string, namespace string, pipeline contract.PipelineInfo) Data
 	FetchGlobal(component string) Data
 	FetchSecretKey(ctx context.Context, name string, namespace string, key string, cache bool) (string, error)
+	FetchConfigMapKey(ctx context.Context, name string, namespace string, key string, cache bool) (string, error)
 	FetchFromFieldOrSecret(ctx context.Context, data *Data, namespace string, fieldKey string, referenceKey string, referenceSecretNameKey string) (string, error)
 }
 
@@ -116,6 +117,31 @@ func (cp *ConfigurationProvider) FetchSecretKey(ctx context.Context, name string
 	return string(val), nil
 }
 
+// FetchConfigMapKey is fetching a key from .data section from a Kubernetes ConfigMap, for non-sensitive values
+// like CA bundles
+func (cp *ConfigurationProvider) FetchConfigMapKey(ctx context.Context, name string,
+	namespace string, key string, cache bool) (string, error) {
+
+	cacheName := "configmap/" + name
+	if cache {
+		if cachedValue := cp.stateStore.GetConfigSecretKey(namespace, cacheName, key); cachedValue != "" {
+			return cachedValue, nil
+		}
+	}
+
+	configMap, err := cp.secretsClient.ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot fetch Kubernetes configmap '%s/%s'", namespace, name)
+	}
+	val, exists := configMap.Data[key]
+	if !exists {
+		return "", errors.New(fmt.Sprintf("the configmap '%s/%s' does not contain key '%s'", namespace, name, key))
+	}
+
+	cp.stateStore.PushConfigSecretKey(namespace, cacheName, key, val) // Update cache
+	return val, nil
+}
+
 // FetchFromFieldOrSecret allows to use an inline secret from configuration file (if present), fallbacks to fetching a secret key from a Kubernetes secret
 func (cp *ConfigurationProvider) FetchFromFieldOrSecret(ctx context.Context, data *Data, namespace string, fieldKey string, referenceKey string, referenceSecretNameKey string) (string, error) {
 	if data.HasKey(fieldKey) {
@@ -123,6 +123,39 @@ func TestConfigurationProvider_FetchSecretKey_FetchesKeyFromKubernetesSecret(t *
 	assert.Equal(t, "blehblehbleh", val)
 }
 
+func TestConfigurationProvider_FetchConfigMapKey_FetchesKeyFromKubernetesConfigMap(t *testing.T) {
+	validator := fake2.NullValidator{}
+	docStore := internalConfig.CreateIndexedDocumentStore(&validator)
+
+	// mock Kubernetes kind: ConfigMap
+	configMap := v12.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "internal-ca",
+			Namespace: "books",
+		},
+		Data: map[string]string{
+			"ca.crt": "-----BEGIN CERTIFICATE-----",
+		},
+	}
+	kubernetesClient := fake.NewSimpleClientset(&configMap)
+
+	cp, err := config.NewConfigurationProvider(
+		docStore,
+		logging.NewInternalLogger(),
+		kubernetesClient.CoreV1(),
+		store.Operator{Store: store.NewMemory()},
+		&validator,
+	)
+	assert.Nil(t, err)
+
+	val, fetchErr := cp.FetchConfigMapKey(context.TODO(), "internal-ca", "books", "ca.crt", true)
+	assert.Nil(t, fetchErr)
+	assert.Equal(t, "-----BEGIN CERTIFICATE-----", val)
+
+	_, missingErr := cp.FetchConfigMapKey(context.TODO(), "internal-ca", "books", "tls.crt", true)
+	assert.Equal(t, "the configmap 'books/internal-ca' does not contain key 'tls.crt'", missingErr.Error())
+}
+
 func TestConfigurationProvider_FetchFromFieldOrSecret_FetchesFromSecret(t *testing.T) {
 	validator := fake2.NullValidator{}
 	docStore := internalConfig.CreateIndexedDocumentStore(&validator)
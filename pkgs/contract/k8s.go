@@ -3,61 +3,215 @@ package contract
 import (
 	"os"
 	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	DefaultAnnotationBase       = "pipelinesfeedback.keskad.pl"
+	DefaultEnabledLabelSelector = "pipelinesfeedback.keskad.pl/enabled=true"
 )
 
+// Conventions describes annotation and label names used by a single controller instance.
+// Zero value is usable and equal to DefaultConventions()
+type Conventions struct {
+	annotationBase  string
+	enabledSelector labels.Selector
+
+	// enabledLabel is set, when no selector expression was given. Then the label value is matched
+	// case-insensitively and with surrounding spaces trimmed, as in previous versions (e.g. "True" is accepted)
+	enabledLabel      string
+	enabledLabelValue string
+}
+
+// NewConventions is a constructor. enabledLabelSelector is a label selector expression
+// e.g. "pipelinesfeedback.keskad.pl/enabled=true" or "ci in (tekton, jobs),!skip-feedback".
+// Empty values fall back to DefaultConventions()
+func NewConventions(annotationBase string, enabledLabelSelector string) (Conventions, error) {
+	if annotationBase == "" {
+		annotationBase = defaultAnnotationBase()
+	}
+	labelName, labelValue := "", ""
+	if enabledLabelSelector == "" {
+		labelName, labelValue = defaultEnabledLabel()
+		enabledLabelSelector = labelName + "=" + labelValue
+	}
+	selector, err := labels.Parse(enabledLabelSelector)
+	if err != nil {
+		return Conventions{}, errors.Wrapf(err, "invalid label selector '%s'", enabledLabelSelector)
+	}
+	return Conventions{annotationBase: annotationBase, enabledSelector: selector, enabledLabel: labelName,
+		enabledLabelValue: labelValue}, nil
+}
+
+// DefaultConventions returns "pipelinesfeedback.keskad.pl" annotations and "pipelinesfeedback.keskad.pl/enabled=true" label selector.
+// Parametrized with deprecated 'ANNOTATION_FEEDBACK_BASE', 'LABEL_FEEDBACK_ENABLED_NAME' and 'LABEL_FEEDBACK_ENABLED_VALUE' env variables
+func DefaultConventions() Conventions {
+	conventions, err := NewConventions("", "")
+	if err != nil {
+		conventions, _ = NewConventions(DefaultAnnotationBase, DefaultEnabledLabelSelector)
+	}
+	return conventions
+}
+
+// defaultAnnotationBase keeps compatibility with 'ANNOTATION_FEEDBACK_BASE' env variable
+func defaultAnnotationBase() string {
+	if val := os.Getenv("ANNOTATION_FEEDBACK_BASE"); val != "" {
+		return val
+	}
+	return DefaultAnnotationBase
+}
+
+// defaultEnabledLabel keeps compatibility with 'LABEL_FEEDBACK_ENABLED_NAME' and 'LABEL_FEEDBACK_ENABLED_VALUE' env variables
+func defaultEnabledLabel() (string, string) {
+	labelName := os.Getenv("LABEL_FEEDBACK_ENABLED_NAME")
+	labelValue := os.Getenv("LABEL_FEEDBACK_ENABLED_VALUE")
+	if labelName == "" {
+		labelName = DefaultAnnotationBase + "/enabled"
+	}
+	if labelValue == "" {
+		labelValue = "true"
+	}
+	return labelName, labelValue
+}
+
+// GetPrIdAnnotation returns by default "pipelinesfeedback.keskad.pl/pr-id"
+func (c Conventions) GetPrIdAnnotation() string {
+	return c.getAnnotationBase() + "/pr-id"
+}
+
+// GetCommmitAnnotation returns by default "pipelinesfeedback.keskad.pl/commit"
+func (c Conventions) GetCommmitAnnotation() string {
+	return c.getAnnotationBase() + "/commit"
+}
+
+// GetHttpsRepoUrlAnnotation returns by default "pipelinesfeedback.keskad.pl/https-repo-url"
+func (c Conventions) GetHttpsRepoUrlAnnotation() string {
+	return c.getAnnotationBase() + "/https-repo-url"
+}
+
+// GetHttpsRepoHostAnnotation returns by default "pipelinesfeedback.keskad.pl/https-repo-host"
+func (c Conventions) GetHttpsRepoHostAnnotation() string {
+	return c.getAnnotationBase() + "/https-repo-host"
+}
+
+// GetRefAnnotation returns by default "pipelinesfeedback.keskad.pl/ref"
+func (c Conventions) GetRefAnnotation() string {
+	return c.getAnnotationBase() + "/ref"
+}
+
+// GetSourceBranchAnnotation returns by default "pipelinesfeedback.keskad.pl/source-branch"
+func (c Conventions) GetSourceBranchAnnotation() string {
+	return c.getAnnotationBase() + "/source-branch"
+}
+
+// GetTargetBranchAnnotation returns by default "pipelinesfeedback.keskad.pl/target-branch"
+func (c Conventions) GetTargetBranchAnnotation() string {
+	return c.getAnnotationBase() + "/target-branch"
+}
+
+// GetTagAnnotation returns by default "pipelinesfeedback.keskad.pl/tag"
+func (c Conventions) GetTagAnnotation() string {
+	return c.getAnnotationBase() + "/tag"
+}
+
+// GetCommitAuthorAnnotation returns by default "pipelinesfeedback.keskad.pl/commit-author"
+func (c Conventions) GetCommitAuthorAnnotation() string {
+	return c.getAnnotationBase() + "/commit-author"
+}
+
+// GetCommitTitleAnnotation returns by default "pipelinesfeedback.keskad.pl/commit-title"
+func (c Conventions) GetCommitTitleAnnotation() string {
+	return c.getAnnotationBase() + "/commit-title"
+}
+
+// GetProjectIdAnnotation returns by default "pipelinesfeedback.keskad.pl/project-id"
+func (c Conventions) GetProjectIdAnnotation() string {
+	return c.getAnnotationBase() + "/project-id"
+}
+
+// GetEnvironmentAnnotation returns by default "pipelinesfeedback.keskad.pl/environment"
+func (c Conventions) GetEnvironmentAnnotation() string {
+	return c.getAnnotationBase() + "/environment"
+}
+
+// GetEnvironmentUrlAnnotation returns by default "pipelinesfeedback.keskad.pl/environment-url"
+func (c Conventions) GetEnvironmentUrlAnnotation() string {
+	return c.getAnnotationBase() + "/environment-url"
+}
+
+// GetTechnicalJobAnnotation returns by default "pipelinesfeedback.keskad.pl/technical-job"
+func (c Conventions) GetTechnicalJobAnnotation() string {
+	return c.getAnnotationBase() + "/technical-job"
+}
+
+// GetEnabledLabelSelector returns a selector that decides which objects are handled by the controller
+func (c Conventions) GetEnabledLabelSelector() labels.Selector {
+	if c.enabledSelector == nil {
+		return DefaultConventions().enabledSelector
+	}
+	return c.enabledSelector
+}
+
+// IsJobHavingRequiredLabel decides if a controller should take the resource
+func (c Conventions) IsJobHavingRequiredLabel(objLabels map[string]string) bool {
+	if c.enabledSelector == nil {
+		return DefaultConventions().IsJobHavingRequiredLabel(objLabels)
+	}
+	if c.enabledLabel != "" {
+		val, ok := objLabels[c.enabledLabel]
+		return ok && strings.TrimSpace(strings.ToLower(val)) == strings.ToLower(c.enabledLabelValue)
+	}
+	return c.enabledSelector.Matches(labels.Set(objLabels))
+}
+
+func (c Conventions) getAnnotationBase() string {
+	if c.annotationBase == "" {
+		return defaultAnnotationBase()
+	}
+	return c.annotationBase
+}
+
 // GetPrIdAnnotation returns by default "pipelinesfeedback.keskad.pl/pr-id". Parametrized with 'ANNOTATION_FEEDBACK_BASE' env variable
+//
+// Deprecated: use Conventions.GetPrIdAnnotation() of the controller instance
 func GetPrIdAnnotation() string {
-	return getAnnotationBase() + "/pr-id"
+	return DefaultConventions().GetPrIdAnnotation()
 }
 
 // GetCommmitAnnotation returns by default "pipelinesfeedback.keskad.pl/commit". Parametrized with 'ANNOTATION_FEEDBACK_BASE' env variable
+//
+// Deprecated: use Conventions.GetCommmitAnnotation() of the controller instance
 func GetCommmitAnnotation() string {
-	return getAnnotationBase() + "/commit"
+	return DefaultConventions().GetCommmitAnnotation()
 }
 
 // GetHttpsRepoUrlAnnotation returns by default "pipelinesfeedback.keskad.pl/https-repo-url". Parametrized with 'ANNOTATION_FEEDBACK_BASE' env variable
+//
+// Deprecated: use Conventions.GetHttpsRepoUrlAnnotation() of the controller instance
 func GetHttpsRepoUrlAnnotation() string {
-	return getAnnotationBase() + "/https-repo-url"
+	return DefaultConventions().GetHttpsRepoUrlAnnotation()
 }
 
 // GetRefAnnotation returns by default "pipelinesfeedback.keskad.pl/ref". Parametrized with 'ANNOTATION_FEEDBACK_BASE' env variable
+//
+// Deprecated: use Conventions.GetRefAnnotation() of the controller instance
 func GetRefAnnotation() string {
-	return getAnnotationBase() + "/ref"
+	return DefaultConventions().GetRefAnnotation()
 }
 
 // GetTechnicalJobAnnotation returns by default "pipelinesfeedback.keskad.pl/technical-job". Parametrized with 'ANNOTATION_FEEDBACK_BASE' env variable
+//
+// Deprecated: use Conventions.GetTechnicalJobAnnotation() of the controller instance
 func GetTechnicalJobAnnotation() string {
-	return getAnnotationBase() + "/technical-job"
-}
-
-func getAnnotationBase() string {
-	if val := os.Getenv("ANNOTATION_FEEDBACK_BASE"); val != "" {
-		return os.Getenv("ANNOTATION_FEEDBACK_BASE")
-	}
-	return "pipelinesfeedback.keskad.pl"
+	return DefaultConventions().GetTechnicalJobAnnotation()
 }
 
-func getFeedbackLabel() (string, string) {
-	labelName := "pipelinesfeedback.keskad.pl/enabled"
-	labelValue := "true"
-
-	if val := os.Getenv("LABEL_FEEDBACK_ENABLED_NAME"); val != "" {
-		labelName = os.Getenv("LABEL_FEEDBACK_ENABLED_NAME")
-	}
-	if val := os.Getenv("LABEL_FEEDBACK_ENABLED_VALUE"); val != "" {
-		labelValue = os.Getenv("LABEL_FEEDBACK_ENABLED_VALUE")
-	}
-	return labelName, labelValue
-}
-
-// IsJobHavingRequiredLabel decides if a controller should take the resource
+// IsJobHavingRequiredLabel decides if a controller should take the resource.
+// Parametrized with 'LABEL_FEEDBACK_ENABLED_NAME' and 'LABEL_FEEDBACK_ENABLED_VALUE' env variables
+//
+// Deprecated: use Conventions.IsJobHavingRequiredLabel() of the controller instance
 func IsJobHavingRequiredLabel(labels map[string]string) bool {
-	requiredLabelName, requiredLabelValue := getFeedbackLabel()
-	// there is a label present
-	if val, ok := labels[requiredLabelName]; ok {
-		// label has required value set
-		return strings.Trim(strings.ToLower(val), " ") == requiredLabelValue
-	}
-	// no label present
-	return false
+	return DefaultConventions().IsJobHavingRequiredLabel(labels)
 }
@@ -0,0 +1,72 @@
+package contract_test
+
+import (
+	"testing"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConventions_ZeroValueIsDefault(t *testing.T) {
+	conventions := contract.Conventions{}
+
+	assert.Equal(t, "pipelinesfeedback.keskad.pl/commit", conventions.GetCommmitAnnotation())
+	assert.True(t, conventions.IsJobHavingRequiredLabel(map[string]string{"pipelinesfeedback.keskad.pl/enabled": "true"}))
+	assert.False(t, conventions.IsJobHavingRequiredLabel(map[string]string{}))
+}
+
+func TestConventions_DefaultLabelIsMatchedCaseInsensitively(t *testing.T) {
+	fromFlags, err := contract.NewConventions("", "")
+	assert.Nil(t, err)
+
+	for _, conventions := range []contract.Conventions{contract.DefaultConventions(), {}, fromFlags} {
+		assert.True(t, conventions.IsJobHavingRequiredLabel(map[string]string{"pipelinesfeedback.keskad.pl/enabled": "True"}))
+		assert.True(t, conventions.IsJobHavingRequiredLabel(map[string]string{"pipelinesfeedback.keskad.pl/enabled": " TRUE "}))
+		assert.False(t, conventions.IsJobHavingRequiredLabel(map[string]string{"pipelinesfeedback.keskad.pl/enabled": "false"}))
+	}
+
+	explicit, _ := contract.NewConventions("", "pipelinesfeedback.keskad.pl/enabled=true")
+	assert.False(t, explicit.IsJobHavingRequiredLabel(map[string]string{"pipelinesfeedback.keskad.pl/enabled": "True"}),
+		"an explicit selector is matched exactly")
+}
+
+func TestNewConventions_CustomAnnotationBase(t *testing.T) {
+	conventions, err := contract.NewConventions("ci.example.org", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ci.example.org/pr-id", conventions.GetPrIdAnnotation())
+	assert.Equal(t, "ci.example.org/https-repo-url", conventions.GetHttpsRepoUrlAnnotation())
+	assert.True(t, conventions.IsJobHavingRequiredLabel(map[string]string{"pipelinesfeedback.keskad.pl/enabled": "true"}))
+}
+
+func TestNewConventions_SelectorExpression(t *testing.T) {
+	conventions, err := contract.NewConventions("", "ci in (tekton, jobs),!skip-feedback")
+
+	assert.Nil(t, err)
+	assert.True(t, conventions.IsJobHavingRequiredLabel(map[string]string{"ci": "tekton"}))
+	assert.True(t, conventions.IsJobHavingRequiredLabel(map[string]string{"ci": "jobs"}))
+	assert.False(t, conventions.IsJobHavingRequiredLabel(map[string]string{"ci": "argo"}))
+	assert.False(t, conventions.IsJobHavingRequiredLabel(map[string]string{"ci": "tekton", "skip-feedback": "true"}))
+}
+
+func TestNewConventions_InvalidSelector(t *testing.T) {
+	_, err := contract.NewConventions("", "ci in (")
+
+	assert.NotNil(t, err)
+}
+
+func TestDefaultConventions_LegacyEnvVariables(t *testing.T) {
+	t.Setenv("ANNOTATION_FEEDBACK_BASE", "ci.example.org")
+	t.Setenv("LABEL_FEEDBACK_ENABLED_NAME", "ci.example.org/feedback")
+
+	fromFlags, err := contract.NewConventions("", "")
+	assert.Nil(t, err)
+
+	for _, conventions := range []contract.Conventions{contract.DefaultConventions(), {}, fromFlags} {
+		assert.Equal(t, "ci.example.org/commit", conventions.GetCommmitAnnotation())
+		assert.True(t, conventions.IsJobHavingRequiredLabel(map[string]string{"ci.example.org/feedback": "true"}))
+		assert.False(t, conventions.IsJobHavingRequiredLabel(map[string]string{"pipelinesfeedback.keskad.pl/enabled": "true"}))
+	}
+	assert.Equal(t, "ci.example.org/pr-id", contract.GetPrIdAnnotation(), "deprecated functions should keep working")
+	assert.True(t, contract.IsJobHavingRequiredLabel(map[string]string{"ci.example.org/feedback": "true"}))
+}
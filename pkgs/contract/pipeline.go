@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -53,6 +54,12 @@ func (pi PipelineInfo) GetSCMContext() JobContext {
 	return pi.ctx
 }
 
+// WithSCMContext returns a copy of PipelineInfo with the JobContext replaced, e.g. enriched with data from the SCM API
+func (pi PipelineInfo) WithSCMContext(ctx JobContext) PipelineInfo {
+	pi.ctx = ctx
+	return pi
+}
+
 // GetStatus is calculating the pipeline status basing on the results of all children stages
 func (pi PipelineInfo) GetStatus() Status {
 	pending := 0
@@ -118,6 +125,11 @@ func (pi PipelineInfo) GetFullName() string {
 	return pi.namespace + "/" + pi.name + "/" + pi.instanceName
 }
 
+// GetDateStarted returns a time when the Pipeline was started, zero value when it is not known
+func (pi PipelineInfo) GetDateStarted() time.Time {
+	return pi.dateStarted
+}
+
 // GetInstanceName is returning execution name, a short name
 func (pi PipelineInfo) GetInstanceName() string {
 	return pi.instanceName
@@ -257,6 +269,10 @@ func (s Status) AsHumanReadableDescription() string {
 type PipelineStage struct {
 	Name   string
 	Status Status
+
+	// StartedAt and FinishedAt are optional, zero value when unknown
+	StartedAt  time.Time
+	FinishedAt time.Time
 }
 
 type JobContext struct {
@@ -272,28 +288,82 @@ type JobContext struct {
 	// PrId is a pull/merge request id
 	PrId string
 
+	// PrDiscovered tells that PrId was not annotated, but found by the commit (one commit can be a head of multiple PRs)
+	PrDiscovered bool
+
 	OrganizationName string
 	RepositoryName   string
 
 	// When a job does not have a SCM context, but has a technical-job annotation
 	TechnicalJob string
+
+	// SourceBranch is a branch name the changes come from (e.g. PR/MR source branch, or a pushed branch)
+	SourceBranch string
+
+	// TargetBranch is a Pull/Merge request target branch name
+	TargetBranch string
+
+	// Tag is a GIT tag name, e.g. derived from Reference 'refs/tags/v1.6.1'
+	Tag string
+
+	// CommitAuthor is a name of the commit author
+	CommitAuthor string
+
+	// CommitTitle is the first line of the commit message
+	CommitTitle string
+
+	// ProjectId is a numeric project identifier (Gitlab)
+	ProjectId string
+
+	// Environment is a name of the environment the Pipeline deploys to, e.g. 'production'
+	Environment string
+
+	// EnvironmentUrl is an address under which the deployed application is available
+	EnvironmentUrl string
+}
+
+// scpLikeUrlRegexp matches SCP-like GIT urls e.g. git@gitlab.example.org:group/subgroup/repository.git
+var scpLikeUrlRegexp = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// SCMContextOptions are optional parameters of NewSCMContext
+type SCMContextOptions struct {
+	httpsHost string
+}
+
+// SCMContextWithHttpsHost is overriding the host (optionally with a port) used in the HTTPS url.
+// Useful when the SCM is served on a non-standard port, or SSH and HTTPS are served on different hosts
+func SCMContextWithHttpsHost(host string) func(opts *SCMContextOptions) {
+	return func(opts *SCMContextOptions) {
+		opts.httpsHost = host
+	}
 }
 
-func NewSCMContext(repoHttpsUrl string) (JobContext, error) {
+// NewSCMContext is creating a JobContext from a repository url. Supported are HTTPS, SSH (ssh://) and SCP-like
+// (git@example.org:org/repo.git) formats. The url is always normalized to HTTPS format
+func NewSCMContext(repoUrl string, options ...func(opts *SCMContextOptions)) (JobContext, error) {
 	scm := JobContext{}
-	scm.RepoHttpsUrl = repoHttpsUrl
+	scm.RepoHttpsUrl = repoUrl
 	scm.OrganizationName = ""
 	scm.RepositoryName = ""
 
 	// not matching, not containing the annotation
-	if len(repoHttpsUrl) == 0 {
+	if len(repoUrl) == 0 {
 		return scm, nil
 	}
 
-	u, err := url.Parse(repoHttpsUrl)
+	opts := SCMContextOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	u, err := parseRepositoryUrl(repoUrl)
 	if err != nil {
 		return scm, errors.Wrap(err, "not a valid url")
 	}
+	if opts.httpsHost != "" {
+		u.Host = opts.httpsHost
+	}
+	scm.RepoHttpsUrl = u.String()
 
 	nameSplit := strings.Split(u.Path, "/")
 	repositoryName := nameSplit[len(nameSplit)-1]
@@ -308,6 +378,25 @@ func NewSCMContext(repoHttpsUrl string) (JobContext, error) {
 	return scm, nil
 }
 
+// parseRepositoryUrl parses any supported GIT url format and translates it into HTTPS url
+func parseRepositoryUrl(repoUrl string) (*url.URL, error) {
+	if !strings.Contains(repoUrl, "://") {
+		if match := scpLikeUrlRegexp.FindStringSubmatch(repoUrl); match != nil {
+			return &url.URL{Scheme: "https", Host: match[1], Path: "/" + strings.TrimLeft(match[2], "/")}, nil
+		}
+	}
+	u, err := url.Parse(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "ssh", "git+ssh", "ssh+git", "git":
+		// SSH port has nothing in common with HTTPS port
+		return &url.URL{Scheme: "https", Host: u.Hostname(), Path: u.Path}, nil
+	}
+	return u, nil
+}
+
 func (c JobContext) IsTechnicalJob() bool {
 	return c.TechnicalJob != ""
 }
@@ -322,3 +411,84 @@ func (c JobContext) IsValid() bool {
 func (c JobContext) GetNameWithOrg() string {
 	return c.OrganizationName + "/" + c.RepositoryName
 }
+
+// InheritFrom fills all empty fields with values taken from other JobContext, e.g. from a parent object.
+// Repository URL, organization and repository names are always inherited together. Commit, PR and GIT metadata
+// are inherited only when both point to the same repository, or the repository is inherited as well
+func (c JobContext) InheritFrom(parent JobContext) JobContext {
+	if c.TechnicalJob == "" {
+		c.TechnicalJob = parent.TechnicalJob
+	}
+	if c.Environment == "" {
+		c.Environment = parent.Environment
+	}
+	if c.EnvironmentUrl == "" {
+		c.EnvironmentUrl = parent.EnvironmentUrl
+	}
+	if c.RepoHttpsUrl != "" && !isSameRepository(c.RepoHttpsUrl, parent.RepoHttpsUrl) {
+		return c
+	}
+
+	if c.RepoHttpsUrl == "" {
+		c.RepoHttpsUrl = parent.RepoHttpsUrl
+		c.OrganizationName = parent.OrganizationName
+		c.RepositoryName = parent.RepositoryName
+	}
+	if c.Commit == "" {
+		c.Commit = parent.Commit
+	}
+	if c.Reference == "" {
+		c.Reference = parent.Reference
+	}
+	if c.PrId == "" {
+		c.PrId = parent.PrId
+	}
+	if c.SourceBranch == "" {
+		c.SourceBranch = parent.SourceBranch
+	}
+	if c.TargetBranch == "" {
+		c.TargetBranch = parent.TargetBranch
+	}
+	if c.Tag == "" {
+		c.Tag = parent.Tag
+	}
+	if c.CommitAuthor == "" {
+		c.CommitAuthor = parent.CommitAuthor
+	}
+	if c.CommitTitle == "" {
+		c.CommitTitle = parent.CommitTitle
+	}
+	if c.ProjectId == "" {
+		c.ProjectId = parent.ProjectId
+	}
+	return c
+}
+
+// isSameRepository compares normalized HTTPS urls, ignoring the optional ".git" suffix and letter case
+func isSameRepository(first string, second string) bool {
+	normalize := func(repoUrl string) string {
+		return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(repoUrl, "/"), ".git"))
+	}
+	return normalize(first) == normalize(second)
+}
+
+// WithReferenceDerivedFields is filling Tag and SourceBranch basing on the Reference, if those are not set yet
+func (c JobContext) WithReferenceDerivedFields() JobContext {
+	if c.Tag == "" && strings.HasPrefix(c.Reference, "refs/tags/") {
+		c.Tag = strings.TrimPrefix(c.Reference, "refs/tags/")
+	}
+	if c.SourceBranch == "" && strings.HasPrefix(c.Reference, "refs/heads/") {
+		c.SourceBranch = strings.TrimPrefix(c.Reference, "refs/heads/")
+	}
+	return c
+}
+
+// IsDeployment tells if the Pipeline deploys to an environment
+func (c JobContext) IsDeployment() bool {
+	return c.Environment != ""
+}
+
+// IsTag tells if the Pipeline was triggered for a GIT tag
+func (c JobContext) IsTag() bool {
+	return c.Tag != ""
+}
@@ -67,3 +67,66 @@ func TestNewSCMContext_WithSimpleGITUrl(t *testing.T) {
 	assert.Equal(t, "bakunin", ctx.RepositoryName)
 	assert.Nil(t, err)
 }
+
+func TestNewSCMContext_WithScpLikeGITUrl(t *testing.T) {
+	ctx, err := contract.NewSCMContext("git@gitlab.example.org:books/anarchism/bakunin.git")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://gitlab.example.org/books/anarchism/bakunin.git", ctx.RepoHttpsUrl)
+	assert.Equal(t, "books/anarchism", ctx.OrganizationName)
+	assert.Equal(t, "bakunin", ctx.RepositoryName)
+}
+
+func TestNewSCMContext_WithSSHGITUrl(t *testing.T) {
+	ctx, err := contract.NewSCMContext("ssh://git@gitlab.example.org:2222/books/anarchism/bakunin.git")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://gitlab.example.org/books/anarchism/bakunin.git", ctx.RepoHttpsUrl, "SSH port should not be used in HTTPS url")
+	assert.Equal(t, "books/anarchism", ctx.OrganizationName)
+	assert.Equal(t, "bakunin", ctx.RepositoryName)
+}
+
+func TestNewSCMContext_WithHttpsHostOverride(t *testing.T) {
+	ctx, err := contract.NewSCMContext("git@gitlab.example.org:books/bakunin.git",
+		contract.SCMContextWithHttpsHost("git.example.org:8443"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://git.example.org:8443/books/bakunin.git", ctx.RepoHttpsUrl)
+	assert.Equal(t, "books", ctx.OrganizationName)
+	assert.Equal(t, "bakunin", ctx.RepositoryName)
+}
+
+func TestNewSCMContext_ScpLikeGITUrlWithoutOrganization(t *testing.T) {
+	_, err := contract.NewSCMContext("git@gitlab.example.org:bakunin.git")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "repository url does not contain valid organization and repository names", err.Error())
+}
+
+func TestJobContext_InheritFrom_SameRepository(t *testing.T) {
+	parent := contract.JobContext{RepoHttpsUrl: "https://github.com/kube-cicd/bakery.git", OrganizationName: "kube-cicd",
+		RepositoryName: "bakery", Commit: "76ea7c7", PrId: "4", SourceBranch: "feature-x"}
+	child := contract.JobContext{RepoHttpsUrl: "https://github.com/kube-cicd/bakery", Commit: "2d6cc28"}
+
+	inherited := child.InheritFrom(parent)
+
+	assert.Equal(t, "2d6cc28", inherited.Commit, "own values should not be overridden")
+	assert.Equal(t, "4", inherited.PrId)
+	assert.Equal(t, "feature-x", inherited.SourceBranch)
+}
+
+func TestJobContext_InheritFrom_DifferentRepository(t *testing.T) {
+	parent := contract.JobContext{RepoHttpsUrl: "https://github.com/kube-cicd/bakery", OrganizationName: "kube-cicd",
+		RepositoryName: "bakery", Commit: "76ea7c7", PrId: "4", Tag: "v1.0", Environment: "production"}
+	child := contract.JobContext{RepoHttpsUrl: "https://github.com/kube-cicd/library", OrganizationName: "kube-cicd",
+		RepositoryName: "library"}
+
+	inherited := child.InheritFrom(parent)
+
+	assert.Equal(t, "https://github.com/kube-cicd/library", inherited.RepoHttpsUrl)
+	assert.Equal(t, "", inherited.Commit, "a commit of other repository does not belong to the child")
+	assert.Equal(t, "", inherited.PrId)
+	assert.Equal(t, "", inherited.Tag)
+	assert.Equal(t, "production", inherited.Environment)
+	assert.False(t, inherited.IsValid())
+}
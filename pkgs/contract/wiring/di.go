@@ -2,10 +2,12 @@ package wiring
 
 import (
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // WithInitialization allows to inject a context granting access to standard services like logging,
@@ -14,6 +16,11 @@ type WithInitialization interface {
 	InitializeWithContext(sc *ServiceContext) error
 }
 
+// WithClosing allows to release resources kept between reconciliations, e.g. open connections, when the controller stops
+type WithClosing interface {
+	Close()
+}
+
 type ServiceContext struct {
 	Recorder     record.EventRecorder
 	KubeConfig   *rest.Config
@@ -21,4 +28,10 @@ type ServiceContext struct {
 	Log          *logging.InternalLogger
 	Store        *store.Operator
 	ConfigSchema config.Validator
+
+	// Conventions are annotation and label names used by this controller instance
+	Conventions contract.Conventions
+
+	// KubeClient is an uncached API reader of the controller manager. Can be nil, when the controller is not run by a manager
+	KubeClient client.Reader
 }
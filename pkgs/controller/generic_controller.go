@@ -34,6 +34,12 @@ type GenericController struct {
 	// simple key-value store
 	Store store.Operator
 
+	// annotation and label names, defaults to contract.DefaultConventions()
+	Conventions contract.Conventions
+
+	// uncached API reader of the controller manager, shared with the PipelineInfoProvider
+	KubeClient client.Reader
+
 	recorder record.EventRecorder
 
 	kubeConfig *rest.Config
@@ -173,7 +179,7 @@ func (gc *GenericController) updateProgress(ctx context.Context, retrieved contr
 
 func (gc *GenericController) SetupWithManager(mgr ctrl.Manager) error {
 	hasLabel := func(obj v1.Object) bool {
-		return contract.IsJobHavingRequiredLabel(obj.GetLabels())
+		return gc.Conventions.IsJobHavingRequiredLabel(obj.GetLabels())
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
@@ -206,6 +212,8 @@ func (gc *GenericController) InjectDependencies(recorder record.EventRecorder, k
 		Log:          logger.ForkWithFields(context.TODO(), map[string]interface{}{}),
 		Store:        &gc.Store,
 		ConfigSchema: cfgSchema,
+		Conventions:  gc.Conventions,
+		KubeClient:   gc.KubeClient,
 	}
 	nErr := func(name string, err error) error {
 		return errors.Wrap(err, fmt.Sprintf("cannot inject dependencies to %s", name))
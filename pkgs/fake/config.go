@@ -38,6 +38,10 @@ func (f *FakeConfigurationProvider) FetchSecretKey(ctx context.Context, name str
 	return "", nil
 }
 
+func (f *FakeConfigurationProvider) FetchConfigMapKey(ctx context.Context, name string, namespace string, key string, cache bool) (string, error) {
+	return "", nil
+}
+
 func (f *FakeConfigurationProvider) FetchFromFieldOrSecret(ctx context.Context, data *config.Data, namespace string, fieldKey string, referenceKey string, referenceSecretNameKey string) (string, error) {
 	return "", nil
 }
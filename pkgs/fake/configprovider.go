@@ -23,6 +23,26 @@ func (cp *ConfigurationProvider) FetchSecretKey(ctx context.Context, name string
 	return "", nil
 }
 
+func (cp *ConfigurationProvider) FetchConfigMapKey(ctx context.Context, name string, namespace string, key string, cache bool) (string, error) {
+	return "", nil
+}
+
 func (cp *ConfigurationProvider) FetchFromFieldOrSecret(ctx context.Context, data *config.Data, namespace string, fieldKey string, referenceKey string, referenceSecretNameKey string) (string, error) {
 	return "", nil
 }
+
+// FieldConfigurationProvider resolves FetchFromFieldOrSecret from the plain fields, as secrets are not reachable in tests
+type FieldConfigurationProvider struct {
+	ConfigurationProvider
+
+	// Fetches counts calls of FetchFromFieldOrSecret
+	Fetches int
+}
+
+func (cp *FieldConfigurationProvider) FetchFromFieldOrSecret(ctx context.Context, data *config.Data, namespace string, fieldKey string, referenceKey string, referenceSecretNameKey string) (string, error) {
+	cp.Fetches++
+	if data.HasKey(fieldKey) {
+		return data.Get(fieldKey), nil
+	}
+	return "", nil
+}
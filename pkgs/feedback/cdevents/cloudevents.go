@@ -0,0 +1,86 @@
+package cdevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ModeBinary     = "binary"
+	ModeStructured = "structured"
+)
+
+// structuredCloudEvent is a CloudEvents 1.0 JSON envelope used in the structured content mode
+type structuredCloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	Id              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            CDEvent   `json:"data"`
+}
+
+// newCloudEventRequest creates a CloudEvents HTTP protocol binding request in structured or binary content mode
+func newCloudEventRequest(ctx context.Context, sinkUrl string, mode string, event CDEvent) (*http.Request, error) {
+	var body []byte
+	var err error
+	contentType := "application/json"
+
+	switch mode {
+	case ModeStructured:
+		contentType = "application/cloudevents+json; charset=UTF-8"
+		body, err = json.Marshal(structuredCloudEvent{
+			SpecVersion:     "1.0",
+			Id:              event.Context.Id,
+			Source:          event.Context.Source,
+			Type:            event.Context.Type,
+			Time:            event.Context.Timestamp,
+			DataContentType: "application/json",
+			Data:            event,
+		})
+	case ModeBinary:
+		body, err = json.Marshal(event)
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported content mode '%s', expected '%s' or '%s'", mode, ModeBinary, ModeStructured))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot encode event")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sinkUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create HTTP request")
+	}
+	req.Header.Set("Content-Type", contentType)
+	if mode == ModeBinary {
+		req.Header.Set("ce-specversion", "1.0")
+		req.Header.Set("ce-id", event.Context.Id)
+		req.Header.Set("ce-source", event.Context.Source)
+		req.Header.Set("ce-type", event.Context.Type)
+		req.Header.Set("ce-time", event.Context.Timestamp.Format(time.RFC3339Nano))
+	}
+	return req, nil
+}
+
+// send delivers the event to the sink. Any 2xx response is considered a success
+func send(client *http.Client, req *http.Request) error {
+	response, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "HTTP request failed")
+	}
+	defer response.Body.Close()
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.New(fmt.Sprintf("sink responded with HTTP %v: %s", response.StatusCode, string(responseBody)))
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package cdevents
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+)
+
+// SpecVersion is the CDEvents specification version the events are compatible with
+const SpecVersion = "0.4.1"
+
+const (
+	PipelineRunQueued   = "dev.cdevents.pipelinerun.queued.0.2.0"
+	PipelineRunStarted  = "dev.cdevents.pipelinerun.started.0.2.0"
+	PipelineRunFinished = "dev.cdevents.pipelinerun.finished.0.2.0"
+	TaskRunStarted      = "dev.cdevents.taskrun.started.0.2.0"
+	TaskRunFinished     = "dev.cdevents.taskrun.finished.0.2.0"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeError   = "error"
+)
+
+// CDEvent is a CDEvents document, sent as a CloudEvent data
+type CDEvent struct {
+	Context          Context     `json:"context"`
+	Subject          Subject     `json:"subject"`
+	CustomData       *CustomData `json:"customData,omitempty"`
+	CustomDataFormat string      `json:"customDataContentType,omitempty"`
+}
+
+type Context struct {
+	Version   string    `json:"version"`
+	Id        string    `json:"id"`
+	Source    string    `json:"source"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type Subject struct {
+	Id      string         `json:"id"`
+	Source  string         `json:"source,omitempty"`
+	Type    string         `json:"type"`
+	Content SubjectContent `json:"content"`
+}
+
+// SubjectContent is a union of pipelineRun and taskRun subject fields
+type SubjectContent struct {
+	PipelineName string     `json:"pipelineName,omitempty"`
+	TaskName     string     `json:"taskName,omitempty"`
+	Url          string     `json:"url,omitempty"`
+	Outcome      string     `json:"outcome,omitempty"`
+	Errors       string     `json:"errors,omitempty"`
+	PipelineRun  *Reference `json:"pipelineRun,omitempty"`
+}
+
+type Reference struct {
+	Id     string `json:"id"`
+	Source string `json:"source,omitempty"`
+}
+
+// CustomData carries the SCM context, which is not a part of pipelineRun and taskRun subjects
+type CustomData struct {
+	Repository   string `json:"repository,omitempty"`
+	Commit       string `json:"commit,omitempty"`
+	Reference    string `json:"reference,omitempty"`
+	PrId         string `json:"prId,omitempty"`
+	SourceBranch string `json:"sourceBranch,omitempty"`
+	TargetBranch string `json:"targetBranch,omitempty"`
+	Tag          string `json:"tag,omitempty"`
+}
+
+// NewPipelineRunEvent creates a pipelinerun.* event
+func NewPipelineRunEvent(eventType string, source string, pipeline contract.PipelineInfo) CDEvent {
+	content := SubjectContent{
+		PipelineName: pipeline.GetName(),
+		Url:          pipeline.GetDashboardUrl(),
+	}
+	if eventType == PipelineRunFinished {
+		content.Outcome, content.Errors = translateOutcome(pipeline.GetStatus())
+	}
+	return newEvent(eventType, source, pipeline, Subject{
+		Id:      pipeline.GetId(),
+		Source:  source,
+		Type:    "pipelineRun",
+		Content: content,
+	})
+}
+
+// NewTaskRunEvent creates a taskrun.* event for a single Pipeline stage
+func NewTaskRunEvent(eventType string, source string, pipeline contract.PipelineInfo, stage contract.PipelineStage) CDEvent {
+	content := SubjectContent{
+		TaskName:    stage.Name,
+		Url:         pipeline.GetDashboardUrl(),
+		PipelineRun: &Reference{Id: pipeline.GetId(), Source: source},
+	}
+	if eventType == TaskRunFinished {
+		content.Outcome, content.Errors = translateOutcome(stage.Status)
+	}
+	return newEvent(eventType, source, pipeline, Subject{
+		Id:      pipeline.GetId() + "/" + stage.Name,
+		Source:  source,
+		Type:    "taskRun",
+		Content: content,
+	})
+}
+
+func newEvent(eventType string, source string, pipeline contract.PipelineInfo, subject Subject) CDEvent {
+	scmCtx := pipeline.GetSCMContext()
+	event := CDEvent{
+		Context: Context{
+			Version:   SpecVersion,
+			Id:        uuid.New().String(),
+			Source:    source,
+			Type:      eventType,
+			Timestamp: time.Now().UTC(),
+		},
+		Subject: subject,
+	}
+	if !scmCtx.IsTechnicalJob() {
+		event.CustomDataFormat = "application/json"
+		event.CustomData = &CustomData{
+			Repository:   scmCtx.RepoHttpsUrl,
+			Commit:       scmCtx.Commit,
+			Reference:    scmCtx.Reference,
+			PrId:         scmCtx.PrId,
+			SourceBranch: scmCtx.SourceBranch,
+			TargetBranch: scmCtx.TargetBranch,
+			Tag:          scmCtx.Tag,
+		}
+	}
+	return event
+}
+
+// translateOutcome maps our status into CDEvents outcome. There is no "cancelled" outcome in the specification
+func translateOutcome(status contract.Status) (string, string) {
+	switch status {
+	case contract.PipelineSucceeded, contract.PipelineSkipped:
+		return OutcomeSuccess, ""
+	case contract.PipelineFailed:
+		return OutcomeFailure, ""
+	case contract.PipelineCancelled:
+		return OutcomeFailure, "cancelled"
+	default:
+		return OutcomeError, status.AsHumanReadableDescription()
+	}
+}
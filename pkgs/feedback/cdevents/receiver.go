@@ -0,0 +1,119 @@
+package cdevents
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/pkg/errors"
+)
+
+// Receiver emits CDEvents as CloudEvents over HTTP. Pipeline maps to a pipelineRun, each stage maps to a taskRun
+type Receiver struct {
+	sc *wiring.ServiceContext
+}
+
+func (cd *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing CDEvents Receiver")
+	cd.sc = sc
+
+	// register configuration options
+	cd.sc.ConfigSchema.Add(config.Schema{
+		Name: "cdevents",
+		AllowedFields: []string{
+			"sink-url",
+			"source",
+			"content-mode",
+			"timeout",
+			"taskrun-events",
+		},
+	})
+	return nil
+}
+
+func (cd *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return cd.emit(ctx, pipeline, log, func(source string) []CDEvent {
+		return []CDEvent{NewPipelineRunEvent(PipelineRunQueued, source, pipeline)}
+	})
+}
+
+func (cd *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return cd.emit(ctx, pipeline, log, func(source string) []CDEvent {
+		return []CDEvent{NewPipelineRunEvent(PipelineRunStarted, source, pipeline)}
+	})
+}
+
+// UpdateProgress emits taskrun.started and taskrun.finished once per stage, as the stages are changing their statuses
+func (cd *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := cd.sc.Config.FetchContextual("cdevents", pipeline.GetNamespace(), pipeline)
+	if cfg.GetOrDefault("taskrun-events", "true") != "true" {
+		return nil
+	}
+	return cd.emit(ctx, pipeline, log, func(source string) []CDEvent {
+		events := make([]CDEvent, 0)
+		for _, stage := range pipeline.GetStages() {
+			// pending and skipped stages were never started
+			if stage.Status.IsNotStarted() {
+				continue
+			}
+			if !stage.Status.IsCancelled() && !cd.sc.Store.WasEventAlreadySent(pipeline, "cdevents/"+TaskRunStarted+"/"+stage.Name) {
+				events = append(events, NewTaskRunEvent(TaskRunStarted, source, pipeline, stage))
+			}
+			if (stage.Status.IsFinished() || stage.Status.IsCancelled()) &&
+				!cd.sc.Store.WasEventAlreadySent(pipeline, "cdevents/"+TaskRunFinished+"/"+stage.Name) {
+				events = append(events, NewTaskRunEvent(TaskRunFinished, source, pipeline, stage))
+			}
+		}
+		return events
+	})
+}
+
+func (cd *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return cd.emit(ctx, pipeline, log, func(source string) []CDEvent {
+		return []CDEvent{NewPipelineRunEvent(PipelineRunFinished, source, pipeline)}
+	})
+}
+
+// emit sends events one-by-one. Already sent events are recorded, so a failure in the middle does not cause duplicates
+func (cd *Receiver) emit(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger, createEvents func(source string) []CDEvent) error {
+	cfg := cd.sc.Config.FetchContextual("cdevents", pipeline.GetNamespace(), pipeline)
+	sinkUrl := cfg.Get("sink-url")
+	if sinkUrl == "" {
+		log.Debugf("Skipping CDEvents, 'cdevents.sink-url' is not configured for '%s'", pipeline.GetId())
+		return nil
+	}
+	timeout, err := time.ParseDuration(cfg.GetOrDefault("timeout", "10s"))
+	if err != nil {
+		return errors.Wrap(err, "invalid 'cdevents.timeout'")
+	}
+	client := &http.Client{Timeout: timeout}
+	mode := cfg.GetOrDefault("content-mode", ModeBinary)
+
+	for _, event := range createEvents(cfg.GetOrDefault("source", "pipelines-feedback-core")) {
+		req, reqErr := newCloudEventRequest(ctx, sinkUrl, mode, event)
+		if reqErr != nil {
+			return errors.Wrapf(reqErr, "cannot create '%s' CloudEvent", event.Context.Type)
+		}
+		if sendErr := send(client, req); sendErr != nil {
+			return errors.Wrapf(sendErr, "cannot send '%s' CloudEvent", event.Context.Type)
+		}
+		log.Debugf("Sent '%s' CloudEvent for '%s'", event.Context.Type, event.Subject.Id)
+
+		if event.Subject.Type == "taskRun" {
+			_ = cd.sc.Store.RecordEventFiring(pipeline, "cdevents/"+event.Context.Type+"/"+event.Subject.Content.TaskName)
+		}
+	}
+	return nil
+}
+
+func (cd *Receiver) CanHandle(name string) bool {
+	return name == cd.GetImplementationName()
+}
+
+func (cd *Receiver) GetImplementationName() string {
+	return "cdevents"
+}
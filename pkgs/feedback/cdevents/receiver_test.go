@@ -0,0 +1,162 @@
+package cdevents_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/cdevents"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type receivedEvent struct {
+	headers http.Header
+	body    []byte
+}
+
+// sink is a local HTTP server collecting CloudEvents
+type sink struct {
+	sync.Mutex
+	server *httptest.Server
+	events []receivedEvent
+}
+
+func newSink() *sink {
+	s := &sink{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.Lock()
+		defer s.Unlock()
+		s.events = append(s.events, receivedEvent{headers: r.Header, body: body})
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	return s
+}
+
+func createPipeline(stages []contract.PipelineStage) contract.PipelineInfo {
+	return *contract.NewPipelineInfo(
+		contract.JobContext{Commit: "76ea7c7", RepoHttpsUrl: "https://github.com/kube-cicd/pipelines-feedback-core", PrId: "161"},
+		"team-1",
+		"bread-pipeline",
+		"bread-pipeline-abc",
+		time.Now(),
+		stages,
+		labels.Set{},
+		labels.Set{},
+		&config.Data{},
+		contract.PipelineInfoWithUrl("https://dashboard.example.org/bread-pipeline-abc"),
+	)
+}
+
+func createReceiver(cfg map[string]string) *cdevents.Receiver {
+	logger := logging.CreateLogger(true)
+	receiver := &cdevents.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.ConfigurationProvider{Contextual: config.NewData("cdevents", cfg, &fake.NullValidator{}, logger)},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+		Store:        &store.Operator{Store: store.NewMemory()},
+	})
+	return receiver
+}
+
+func TestReceiver_BinaryContentMode(t *testing.T) {
+	s := newSink()
+	defer s.server.Close()
+
+	receiver := createReceiver(map[string]string{"sink-url": s.server.URL, "source": "/clusters/bakery"})
+	pipeline := createPipeline([]contract.PipelineStage{{Name: "bake", Status: contract.PipelineFailed}})
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), pipeline, logging.CreateLogger(true)))
+
+	assert.Len(t, s.events, 1)
+	assert.Equal(t, "1.0", s.events[0].headers.Get("ce-specversion"))
+	assert.Equal(t, cdevents.PipelineRunFinished, s.events[0].headers.Get("ce-type"))
+	assert.Equal(t, "/clusters/bakery", s.events[0].headers.Get("ce-source"))
+	assert.Equal(t, "application/json", s.events[0].headers.Get("Content-Type"))
+
+	event := cdevents.CDEvent{}
+	assert.Nil(t, json.Unmarshal(s.events[0].body, &event))
+	assert.Equal(t, s.events[0].headers.Get("ce-id"), event.Context.Id)
+	assert.Equal(t, "pipelineRun", event.Subject.Type)
+	assert.Equal(t, "team-1/bread-pipeline", event.Subject.Content.PipelineName)
+	assert.Equal(t, cdevents.OutcomeFailure, event.Subject.Content.Outcome)
+	assert.Equal(t, "https://dashboard.example.org/bread-pipeline-abc", event.Subject.Content.Url)
+	assert.Equal(t, "76ea7c7", event.CustomData.Commit)
+}
+
+func TestReceiver_StructuredContentMode(t *testing.T) {
+	s := newSink()
+	defer s.server.Close()
+
+	receiver := createReceiver(map[string]string{"sink-url": s.server.URL, "content-mode": "structured"})
+	pipeline := createPipeline([]contract.PipelineStage{{Name: "bake", Status: contract.PipelinePending}})
+	assert.Nil(t, receiver.WhenCreated(context.TODO(), pipeline, logging.CreateLogger(true)))
+
+	assert.Len(t, s.events, 1)
+	assert.Contains(t, s.events[0].headers.Get("Content-Type"), "application/cloudevents+json")
+	assert.Empty(t, s.events[0].headers.Get("ce-type"))
+
+	envelope := struct {
+		SpecVersion string           `json:"specversion"`
+		Type        string           `json:"type"`
+		Id          string           `json:"id"`
+		Data        cdevents.CDEvent `json:"data"`
+	}{}
+	assert.Nil(t, json.Unmarshal(s.events[0].body, &envelope))
+	assert.Equal(t, "1.0", envelope.SpecVersion)
+	assert.Equal(t, cdevents.PipelineRunQueued, envelope.Type)
+	assert.Equal(t, envelope.Id, envelope.Data.Context.Id)
+	assert.Equal(t, "team-1/bread-pipeline/bread-pipeline-abc", envelope.Data.Subject.Id)
+}
+
+func TestReceiver_TaskRunEventsAreSentOncePerStage(t *testing.T) {
+	s := newSink()
+	defer s.server.Close()
+	receiver := createReceiver(map[string]string{"sink-url": s.server.URL})
+
+	// 1st reconciliation: first stage is running
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline([]contract.PipelineStage{
+		{Name: "knead", Status: contract.PipelineRunning},
+		{Name: "bake", Status: contract.PipelinePending},
+	}), logging.CreateLogger(true)))
+
+	// 2nd reconciliation: first stage finished, second one is running
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline([]contract.PipelineStage{
+		{Name: "knead", Status: contract.PipelineSucceeded},
+		{Name: "bake", Status: contract.PipelineRunning},
+	}), logging.CreateLogger(true)))
+
+	types := make([]string, 0)
+	for _, event := range s.events {
+		types = append(types, event.headers.Get("ce-type"))
+	}
+	assert.Equal(t, []string{
+		cdevents.TaskRunStarted,
+		cdevents.TaskRunFinished,
+		cdevents.TaskRunStarted,
+	}, types)
+
+	lastEvent := cdevents.CDEvent{}
+	assert.Nil(t, json.Unmarshal(s.events[2].body, &lastEvent))
+	assert.Equal(t, "bake", lastEvent.Subject.Content.TaskName)
+	assert.Equal(t, "team-1/bread-pipeline/bread-pipeline-abc", lastEvent.Subject.Content.PipelineRun.Id)
+}
+
+func TestReceiver_NotConfigured(t *testing.T) {
+	receiver := createReceiver(map[string]string{})
+	pipeline := createPipeline([]contract.PipelineStage{{Name: "bake", Status: contract.PipelineRunning}})
+
+	assert.Nil(t, receiver.WhenStarted(context.TODO(), pipeline, logging.CreateLogger(true)))
+}
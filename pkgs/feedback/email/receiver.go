@@ -0,0 +1,228 @@
+package email
+
+import (
+	"context"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
+	"github.com/pkg/errors"
+)
+
+const defaultSubject = `[{{ .pipeline.GetStatus }}] Pipeline {{ .pipeline.GetFullName }}`
+
+const defaultTextBody = `The Pipeline '{{ .pipeline.GetInstanceName }}' in namespace '{{ .pipeline.GetNamespace }}' finished with status '{{ .pipeline.GetStatus }}'.
+{{ if .pipeline.GetSCMContext.Commit }}
+Repository: {{ .pipeline.GetSCMContext.RepoHttpsUrl }}
+Commit: {{ .pipeline.GetSCMContext.Commit }}
+{{ end }}
+Stages:
+{{- range $stage := .pipeline.GetStages }}
+- {{ $stage.Name }}: {{ $stage.Status }}
+{{- end }}
+{{ if .pipeline.GetDashboardUrl }}
+Dashboard: {{ .pipeline.GetDashboardUrl }}
+{{ end }}
+{{- if .logs }}
+Build logs:
+{{ .logs }}
+{{ end }}`
+
+const defaultHtmlBody = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif">
+	<h2 style="color: {{ if .pipeline.GetStatus.IsSucceeded }}#1a7f37{{ else }}#cf222e{{ end }}">
+		Pipeline {{ .pipeline.GetInstanceName }} {{ .pipeline.GetStatus.AsHumanReadableDescription }}
+	</h2>
+	<p>Namespace: <code>{{ .pipeline.GetNamespace }}</code></p>
+	{{ if .pipeline.GetSCMContext.Commit }}
+	<p>Repository: {{ .pipeline.GetSCMContext.RepoHttpsUrl }}<br/>Commit: <code>{{ .pipeline.GetSCMContext.Commit }}</code></p>
+	{{ end }}
+	<table border="1" cellpadding="4" style="border-collapse: collapse">
+		<tr><th>Stage</th><th>Status</th></tr>
+		{{ range $stage := .pipeline.GetStages }}
+		<tr><td>{{ $stage.Name }}</td><td>{{ $stage.Status }}</td></tr>
+		{{ end }}
+	</table>
+	{{ if .pipeline.GetDashboardUrl }}<p><a href="{{ .pipeline.GetDashboardUrl }}">Open in dashboard</a></p>{{ end }}
+	{{ if .logs }}<h3>Build logs</h3><pre>{{ .logs }}</pre>{{ end }}
+</body>
+</html>`
+
+// Receiver sends an e-mail when the Pipeline finishes. Does not require a SCM context, so it is suitable for technical jobs
+type Receiver struct {
+	sc *wiring.ServiceContext
+}
+
+func (e *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing E-mail Receiver")
+	e.sc = sc
+
+	// register configuration options
+	e.sc.ConfigSchema.Add(config.Schema{
+		Name: "email",
+		AllowedFields: []string{
+			"smtp-host",
+			"smtp-port",
+			"tls-mode",
+			"username",
+			"password",
+			"password-secret-name",
+			"password-secret-key",
+			"from",
+			"to",
+			"to-annotations",
+			"only-on-failure",
+			"subject",
+			"text-body",
+			"html-body",
+			"logs-max-length",
+			"timeout",
+		},
+	})
+	return nil
+}
+
+func (e *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+func (e *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+func (e *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+// WhenFinished sends a summary e-mail to recipients from configuration and annotations
+func (e *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := e.sc.Config.FetchContextual("email", pipeline.GetNamespace(), pipeline)
+	if cfg.Get("smtp-host") == "" {
+		log.Debugf("Skipping e-mail, 'email.smtp-host' is not configured for '%s'", pipeline.GetId())
+		return nil
+	}
+	if cfg.GetOrDefault("only-on-failure", "false") == "true" && !pipeline.GetStatus().IsErroredOrFailed() {
+		log.Debugf("Skipping e-mail, Pipeline '%s' did not fail", pipeline.GetId())
+		return nil
+	}
+	recipients := resolveRecipients(cfg, pipeline, log)
+	if len(recipients) == 0 {
+		log.Warningf("Skipping e-mail, no recipients resolved for '%s'", pipeline.GetId())
+		return nil
+	}
+
+	settings, err := e.createSmtpSettings(ctx, cfg, pipeline)
+	if err != nil {
+		return err
+	}
+	msg, err := e.createMessage(cfg, recipients, pipeline)
+	if err != nil {
+		return err
+	}
+	if err := sendMail(ctx, settings, cfg.Get("from"), recipients, msg); err != nil {
+		return errors.Wrap(err, "cannot send an e-mail")
+	}
+	return nil
+}
+
+func (e *Receiver) createMessage(cfg config.Data, recipients []string, pipeline contract.PipelineInfo) ([]byte, error) {
+	if cfg.Get("from") == "" {
+		return nil, errors.New("'email.from' is required")
+	}
+	logsMaxLength, _ := strconv.Atoi(cfg.GetOrDefault("logs-max-length", "20000"))
+	variables := map[string]interface{}{
+		"logs": templating.Tail(pipeline.GetLogs(), logsMaxLength),
+	}
+
+	subject, err := templating.TemplateChatMessage(cfg.GetOrDefault("subject", defaultSubject), "email-subject", pipeline, variables)
+	if err != nil {
+		return nil, err
+	}
+	textBody, err := templating.TemplateChatMessage(cfg.GetOrDefault("text-body", defaultTextBody), "email-text-body", pipeline, variables)
+	if err != nil {
+		return nil, err
+	}
+	htmlBody, err := templating.TemplateHtml(cfg.GetOrDefault("html-body", defaultHtmlBody), "email-html-body", pipeline, variables)
+	if err != nil {
+		return nil, err
+	}
+	return encodeMessage(cfg.Get("from"), recipients, strings.TrimSpace(subject), textBody, htmlBody)
+}
+
+func (e *Receiver) createSmtpSettings(ctx context.Context, cfg config.Data, pipeline contract.PipelineInfo) (smtpSettings, error) {
+	port, err := strconv.Atoi(cfg.GetOrDefault("smtp-port", "587"))
+	if err != nil {
+		return smtpSettings{}, errors.Wrap(err, "invalid 'email.smtp-port'")
+	}
+	timeout, err := time.ParseDuration(cfg.GetOrDefault("timeout", "30s"))
+	if err != nil {
+		return smtpSettings{}, errors.Wrap(err, "invalid 'email.timeout'")
+	}
+	tlsMode := cfg.GetOrDefault("tls-mode", TlsModeStartTls)
+	if tlsMode != TlsModeStartTls && tlsMode != TlsModeTls && tlsMode != TlsModeNone {
+		return smtpSettings{}, errors.New("invalid 'email.tls-mode', expected one of: starttls, tls, none")
+	}
+
+	// "email.password" as plaintext, or a `kind: Secret` referenced by "email.password-secret-name" and "email.password-secret-key"
+	password := ""
+	if cfg.Get("username") != "" {
+		password, err = e.sc.Config.FetchFromFieldOrSecret(ctx, &cfg, pipeline.GetNamespace(), "password", "password-secret-key", "password-secret-name")
+		if err != nil {
+			return smtpSettings{}, errors.Wrap(err, "cannot fetch SMTP password neither from 'email.password' as plaintext neither from a `kind: Secret` referenced in 'email.password-secret-name'")
+		}
+	}
+
+	return smtpSettings{
+		host:     cfg.Get("smtp-host"),
+		port:     port,
+		tlsMode:  tlsMode,
+		username: cfg.Get("username"),
+		password: password,
+		timeout:  timeout,
+	}, nil
+}
+
+func (e *Receiver) CanHandle(name string) bool {
+	return name == e.GetImplementationName()
+}
+
+func (e *Receiver) GetImplementationName() string {
+	return "email"
+}
+
+// resolveRecipients collects addresses from "email.to" and from annotations listed in "email.to-annotations"
+func resolveRecipients(cfg config.Data, pipeline contract.PipelineInfo, log *logging.InternalLogger) []string {
+	candidates := strings.Split(cfg.Get("to"), ",")
+	for _, annotation := range strings.Split(cfg.Get("to-annotations"), ",") {
+		annotation = strings.TrimSpace(annotation)
+		if annotation != "" && pipeline.GetAnnotations() != nil && pipeline.GetAnnotations().Has(annotation) {
+			candidates = append(candidates, strings.Split(pipeline.GetAnnotations().Get(annotation), ",")...)
+		}
+	}
+
+	recipients := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		address, err := mail.ParseAddress(candidate)
+		if err != nil {
+			log.Warningf("Ignoring invalid e-mail address '%s': %s", candidate, err.Error())
+			continue
+		}
+		if !seen[address.Address] {
+			seen[address.Address] = true
+			recipients = append(recipients, address.Address)
+		}
+	}
+	return recipients
+}
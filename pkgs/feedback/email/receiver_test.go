@@ -0,0 +1,199 @@
+package email_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/email"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// smtpStandIn is a minimal plaintext SMTP server recording the delivered messages
+type smtpStandIn struct {
+	sync.Mutex
+	listener   net.Listener
+	auth       string
+	from       string
+	recipients []string
+	data       string
+}
+
+func newSmtpStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	s := &smtpStandIn{listener: listener}
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) port() string {
+	return strconv.Itoa(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 bakery.local ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		s.Lock()
+		switch command {
+		case "EHLO", "HELO":
+			reply("250-bakery.local")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			decoded, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			s.auth = string(decoded)
+			reply("235 Authentication successful")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.recipients = append(s.recipients, line)
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, _ := reader.ReadString('\n')
+				if dataLine == ".\r\n" || dataLine == "" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.data = data.String()
+			reply("250 OK: queued")
+		case "QUIT":
+			reply("221 Bye")
+			s.Unlock()
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+		s.Unlock()
+	}
+}
+
+func createPipeline(status contract.Status, annotations labels.Set) contract.PipelineInfo {
+	globalCfg := config.NewData("global", map[string]string{}, &fake.NullValidator{}, logging.CreateLogger(true))
+	return *contract.NewPipelineInfo(
+		contract.JobContext{TechnicalJob: "nightly-backup"},
+		"team-1",
+		"nightly-backup",
+		"nightly-backup-28374",
+		time.Now(),
+		[]contract.PipelineStage{{Name: "dump", Status: status}},
+		labels.Set{},
+		annotations,
+		&globalCfg,
+		contract.PipelineInfoWithLogsCollector(func() string {
+			return "pg_dump: <error> connection refused"
+		}),
+	)
+}
+
+func createReceiver(cfg map[string]string) *email.Receiver {
+	logger := logging.CreateLogger(true)
+	receiver := &email.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.FieldConfigurationProvider{ConfigurationProvider: fake.ConfigurationProvider{Contextual: config.NewData("email", cfg, &fake.NullValidator{}, logger)}},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+	})
+	return receiver
+}
+
+func TestReceiver_SendsEmailForTechnicalJob(t *testing.T) {
+	server := newSmtpStandIn(t)
+	defer server.listener.Close()
+
+	receiver := createReceiver(map[string]string{
+		"smtp-host":      "127.0.0.1",
+		"smtp-port":      server.port(),
+		"tls-mode":       "none",
+		"username":       "bakery",
+		"password":       "croissant",
+		"from":           "ci@bakery.local",
+		"to":             "ops@bakery.local, Ops <ops@bakery.local>",
+		"to-annotations": "bakery.local/owner-team",
+	})
+	pipeline := createPipeline(contract.PipelineFailed, labels.Set{"bakery.local/owner-team": "dough-team@bakery.local,not-an-address"})
+	err := receiver.WhenFinished(context.TODO(), pipeline, logging.CreateLogger(true))
+
+	assert.Nil(t, err)
+	server.Lock()
+	defer server.Unlock()
+	assert.Equal(t, "\x00bakery\x00croissant", server.auth)
+	assert.Equal(t, "MAIL FROM:<ci@bakery.local>", server.from)
+	assert.Equal(t, []string{"RCPT TO:<ops@bakery.local>", "RCPT TO:<dough-team@bakery.local>"}, server.recipients)
+	assert.Contains(t, server.data, "Subject: [failed] Pipeline team-1/nightly-backup/nightly-backup-28374")
+	assert.Contains(t, server.data, "multipart/alternative")
+	assert.Contains(t, server.data, "text/plain")
+	assert.Contains(t, server.data, "pg_dump: <error> connection refused")       // text part is not escaped
+	assert.Contains(t, server.data, "pg_dump: &lt;error&gt; connection refused") // html part is escaped
+}
+
+func TestReceiver_OnlyOnFailure(t *testing.T) {
+	server := newSmtpStandIn(t)
+	defer server.listener.Close()
+
+	receiver := createReceiver(map[string]string{
+		"smtp-host":       "127.0.0.1",
+		"smtp-port":       server.port(),
+		"tls-mode":        "none",
+		"from":            "ci@bakery.local",
+		"to":              "ops@bakery.local",
+		"only-on-failure": "true",
+	})
+	err := receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineSucceeded, labels.Set{}), logging.CreateLogger(true))
+
+	assert.Nil(t, err)
+	server.Lock()
+	defer server.Unlock()
+	assert.Empty(t, server.data)
+}
+
+func TestReceiver_RequiresStartTlsByDefault(t *testing.T) {
+	server := newSmtpStandIn(t)
+	defer server.listener.Close()
+
+	receiver := createReceiver(map[string]string{
+		"smtp-host": "127.0.0.1",
+		"smtp-port": server.port(),
+		"from":      "ci@bakery.local",
+		"to":        "ops@bakery.local",
+	})
+	err := receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineFailed, labels.Set{}), logging.CreateLogger(true))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "does not support STARTTLS")
+}
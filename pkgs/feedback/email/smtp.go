@@ -0,0 +1,88 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	TlsModeStartTls = "starttls"
+	TlsModeTls      = "tls"
+	TlsModeNone     = "none"
+)
+
+type smtpSettings struct {
+	host     string
+	port     int
+	tlsMode  string
+	username string
+	password string
+	timeout  time.Duration
+}
+
+// sendMail delivers an already encoded message. Supports implicit TLS, STARTTLS and plaintext connections, optionally with PLAIN auth
+func sendMail(ctx context.Context, settings smtpSettings, from string, to []string, msg []byte) error {
+	address := net.JoinHostPort(settings.host, strconv.Itoa(settings.port))
+	dialer := &net.Dialer{Timeout: settings.timeout}
+	tlsConfig := &tls.Config{ServerName: settings.host}
+
+	var conn net.Conn
+	var err error
+	if settings.tlsMode == TlsModeTls {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot connect to SMTP server '%s'", address)
+	}
+	_ = conn.SetDeadline(time.Now().Add(settings.timeout))
+
+	client, err := smtp.NewClient(conn, settings.host)
+	if err != nil {
+		_ = conn.Close()
+		return errors.Wrap(err, "cannot initialize SMTP session")
+	}
+	defer client.Close()
+
+	if settings.tlsMode == TlsModeStartTls {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New(fmt.Sprintf("SMTP server '%s' does not support STARTTLS. Use 'email.tls-mode' to change the connection mode", address))
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return errors.Wrap(err, "STARTTLS failed")
+		}
+	}
+	if settings.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", settings.username, settings.password, settings.host)); err != nil {
+			return errors.Wrap(err, "SMTP authentication failed")
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return errors.Wrapf(err, "SMTP server rejected sender '%s'", from)
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return errors.Wrapf(err, "SMTP server rejected recipient '%s'", recipient)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "SMTP server rejected DATA command")
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return errors.Wrap(err, "cannot write message body")
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "SMTP server did not accept the message")
+	}
+	return client.Quit()
+}
@@ -0,0 +1,102 @@
+package githubchecks
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxAnnotationsPerRequest is a limit of the GitHub Checks API
+const MaxAnnotationsPerRequest = 50
+
+// Annotation points to a line in a file, shown by GitHub in the "Files changed" view
+type Annotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	StartColumn     int    `json:"start_column,omitempty"`
+	EndColumn       int    `json:"end_column,omitempty"`
+	AnnotationLevel string `json:"annotation_level"`
+	Message         string `json:"message"`
+}
+
+// annotationFormats are regexps with named groups: path, line, column (optional), level (optional) and message
+var annotationFormats = []*regexp.Regexp{
+	// Maven: [ERROR] /workspace/src/main/java/Bread.java:[12,5] cannot find symbol
+	regexp.MustCompile(`^\[(?P<level>ERROR|WARNING)\]\s+(?P<path>[^\s\[\]]+\.\w+):\[(?P<line>\d+),(?P<column>\d+)\]\s*(?P<message>.+)$`),
+	// TypeScript, MSBuild: src/bread.ts(12,5): error TS2322: Type 'string' is not assignable to type 'number'
+	regexp.MustCompile(`^\s*(?P<path>[^\s():]+\.\w+)\((?P<line>\d+),(?P<column>\d+)\):\s*(?P<level>error|warning)\s*(?P<message>.+)$`),
+	// Go, GCC, Clang, javac, pytest, ESLint (unix): pkg/bread.go:12:5: undefined: flour
+	// also Go tests: bread_test.go:12: expected 'sourdough', got 'baguette'
+	regexp.MustCompile(`^\s*(?P<path>[^\s():]+\.\w+):(?P<line>\d+):(?:(?P<column>\d+):)?\s*(?:(?P<level>error|warning|note)\s*:\s*)?(?P<message>.+)$`),
+}
+
+// ParseAnnotations finds compiler and test errors in the logs, so they can be shown next to the code.
+// Path prefix (e.g. the workspace directory) is stripped, so paths are relative to the repository root
+func ParseAnnotations(logs string, stripPrefix string, maxCount int) []Annotation {
+	annotations := make([]Annotation, 0)
+	known := make(map[string]bool)
+	if stripPrefix != "" && !strings.HasSuffix(stripPrefix, "/") {
+		stripPrefix += "/"
+	}
+
+	for _, line := range strings.Split(logs, "\n") {
+		if len(annotations) >= maxCount {
+			break
+		}
+		annotation, ok := parseLine(strings.TrimRight(line, "\r"))
+		if !ok {
+			continue
+		}
+		annotation.Path = strings.TrimPrefix(strings.TrimPrefix(annotation.Path, stripPrefix), "./")
+		if strings.HasPrefix(annotation.Path, "/") || annotation.Path == "" {
+			continue
+		}
+
+		ident := annotation.Path + ":" + strconv.Itoa(annotation.StartLine) + ":" + annotation.Message
+		if known[ident] {
+			continue
+		}
+		known[ident] = true
+		annotations = append(annotations, annotation)
+	}
+	return annotations
+}
+
+func parseLine(line string) (Annotation, bool) {
+	for _, format := range annotationFormats {
+		match := format.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		groups := make(map[string]string)
+		for i, name := range format.SubexpNames() {
+			if name != "" {
+				groups[name] = match[i]
+			}
+		}
+		lineNum, _ := strconv.Atoi(groups["line"])
+		column, _ := strconv.Atoi(groups["column"])
+		return Annotation{
+			Path:            groups["path"],
+			StartLine:       lineNum,
+			EndLine:         lineNum,
+			StartColumn:     column,
+			EndColumn:       column,
+			AnnotationLevel: translateLevel(groups["level"]),
+			Message:         strings.TrimSpace(groups["message"]),
+		}, true
+	}
+	return Annotation{}, false
+}
+
+func translateLevel(level string) string {
+	switch strings.ToLower(level) {
+	case "warning":
+		return "warning"
+	case "note":
+		return "notice"
+	default:
+		return "failure"
+	}
+}
@@ -0,0 +1,44 @@
+package githubchecks_test
+
+import (
+	"testing"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/githubchecks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	logs := `Step 1/3 : go build ./...
+# github.com/kube-cicd/bakery/pkg
+/workspace/source/pkg/oven.go:12:5: undefined: flour
+--- FAIL: TestBake (0.00s)
+    bread_test.go:21: expected 'sourdough', got 'baguette'
+src/bread.ts(8,14): error TS2322: Type 'string' is not assignable to type 'number'.
+[ERROR] /workspace/source/src/main/java/Bread.java:[30,7] cannot find symbol
+./pkg/crust.c:3:1: warning: implicit declaration of function 'knead'
+/workspace/source/pkg/oven.go:12:5: undefined: flour
+Started at 12:30:45
+Downloading https://proxy.golang.org:443/github.com/pkg/errors
+`
+	annotations := githubchecks.ParseAnnotations(logs, "/workspace/source/", 50)
+
+	assert.Equal(t, []githubchecks.Annotation{
+		{Path: "pkg/oven.go", StartLine: 12, EndLine: 12, StartColumn: 5, EndColumn: 5, AnnotationLevel: "failure", Message: "undefined: flour"},
+		{Path: "bread_test.go", StartLine: 21, EndLine: 21, AnnotationLevel: "failure", Message: "expected 'sourdough', got 'baguette'"},
+		{Path: "src/bread.ts", StartLine: 8, EndLine: 8, StartColumn: 14, EndColumn: 14, AnnotationLevel: "failure", Message: "TS2322: Type 'string' is not assignable to type 'number'."},
+		{Path: "src/main/java/Bread.java", StartLine: 30, EndLine: 30, StartColumn: 7, EndColumn: 7, AnnotationLevel: "failure", Message: "cannot find symbol"},
+		{Path: "pkg/crust.c", StartLine: 3, EndLine: 3, StartColumn: 1, EndColumn: 1, AnnotationLevel: "warning", Message: "implicit declaration of function 'knead'"},
+	}, annotations)
+}
+
+func TestParseAnnotations_RespectsLimit(t *testing.T) {
+	logs := "oven.go:1: too hot\noven.go:2: too cold\noven.go:3: just right\n"
+
+	assert.Len(t, githubchecks.ParseAnnotations(logs, "", 2), 2)
+}
+
+func TestParseAnnotations_SkipsAbsolutePathsOutsideOfRepository(t *testing.T) {
+	logs := "/usr/local/go/src/runtime/panic.go:770: panic\n"
+
+	assert.Empty(t, githubchecks.ParseAnnotations(logs, "/workspace/source", 50))
+}
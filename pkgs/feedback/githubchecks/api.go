@@ -0,0 +1,67 @@
+package githubchecks
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/githubapp"
+)
+
+// checkRun is a request body of the Checks API "create" and "update" endpoints
+type checkRun struct {
+	Name        string     `json:"name"`
+	HeadSha     string     `json:"head_sha,omitempty"`
+	DetailsUrl  string     `json:"details_url,omitempty"`
+	ExternalId  string     `json:"external_id,omitempty"`
+	Status      string     `json:"status"`
+	Conclusion  string     `json:"conclusion,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Output      *output    `json:"output,omitempty"`
+
+	// completedAt is sent only for completed runs, and is not a part of the content checksum
+	completedAt time.Time
+}
+
+type output struct {
+	Title       string       `json:"title"`
+	Summary     string       `json:"summary"`
+	Text        string       `json:"text,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+type checkRunResponse struct {
+	Id int64 `json:"id"`
+}
+
+// apiClient is a minimal GitHub Checks API client, authenticated with an installation token
+type apiClient struct {
+	baseUrl string
+	token   string
+	client  *http.Client
+}
+
+func newApiClient(baseUrl string, token string) *apiClient {
+	return &apiClient{baseUrl: baseUrl, token: token, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// createCheckRun creates a Check Run for a commit, returns its id
+func (c *apiClient) createCheckRun(ctx context.Context, repository string, run checkRun) (string, error) {
+	response := checkRunResponse{}
+	if err := c.call(ctx, http.MethodPost, "/repos/"+repository+"/check-runs", run, &response); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(response.Id, 10), nil
+}
+
+// updateCheckRun updates the existing Check Run. Annotations are appended to the already existing ones
+func (c *apiClient) updateCheckRun(ctx context.Context, repository string, id string, run checkRun) error {
+	run.HeadSha = ""
+	return c.call(ctx, http.MethodPatch, "/repos/"+repository+"/check-runs/"+id, run, &checkRunResponse{})
+}
+
+func (c *apiClient) call(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	return githubapp.CallAPI(ctx, c.client, c.baseUrl, c.token, method, path, in, out)
+}
@@ -0,0 +1,313 @@
+package githubchecks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/githubapp"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
+	"github.com/pkg/errors"
+)
+
+const (
+	ModePipeline = "pipeline"
+	ModeStage    = "stage"
+)
+
+const defaultName = `{{ .pipeline.GetName }}`
+const defaultStageName = `{{ .pipeline.GetName }} / {{ .stage.Name }}`
+
+const defaultSummary = `
+{{ if .stage }}Stage **{{ .stage.Name }}** {{ .stage.Status.AsHumanReadableDescription }}, the Pipeline{{ else }}The Pipeline{{ end }} **{{ .pipeline.GetInstanceName }}** {{ .pipeline.GetStatus.AsHumanReadableDescription }}
+
+| Stage | Status |
+|-------|--------|
+{{- range $stage := .pipeline.GetStages }}
+| {{ $stage.Name }} | {{ $stage.Status }} |
+{{- end }}
+
+{{ if .pipeline.GetDashboardUrl }}[Open in dashboard]({{ .pipeline.GetDashboardUrl }}){{ end }}
+`
+
+// Receiver reports Pipelines as GitHub Check Runs, authenticating as a GitHub App. A Check Run is created per Pipeline,
+// or per stage, then updated on every change. Finished runs contain the logs excerpt and annotations found in the logs
+type Receiver struct {
+	sc *wiring.ServiceContext
+}
+
+func (r *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing GitHub Checks Receiver")
+	r.sc = sc
+
+	// register configuration options
+	r.sc.ConfigSchema.Add(config.Schema{
+		Name: "githubchecks",
+		AllowedFields: []string{
+			"app-id",
+			"private-key",
+			"private-key-secret-name",
+			"private-key-secret-key",
+			"installation-id",
+			"api-url",
+			"mode",
+			"name",
+			"stage-name",
+			"summary",
+			"logs-max-length",
+			"annotations-strip-prefix",
+		},
+	})
+	return nil
+}
+
+func (r *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+func (r *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+// UpdateProgress creates the Check Runs, then keeps them up-to-date with the Pipeline status
+func (r *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return r.publish(ctx, pipeline, false, log)
+}
+
+// WhenFinished completes the Check Runs with the logs excerpt and annotations
+func (r *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return r.publish(ctx, pipeline, true, log)
+}
+
+func (r *Receiver) publish(ctx context.Context, pipeline contract.PipelineInfo, withLogs bool, log *logging.InternalLogger) error {
+	scmCtx := pipeline.GetSCMContext()
+	if scmCtx.IsTechnicalJob() || scmCtx.Commit == "" || scmCtx.RepositoryName == "" {
+		log.Debugf("Skipping GitHub Checks, no repository or commit for '%s'", pipeline.GetId())
+		return nil
+	}
+	cfg := r.sc.Config.FetchContextual("githubchecks", pipeline.GetNamespace(), pipeline)
+	if cfg.Get("app-id") == "" {
+		log.Debugf("Skipping GitHub Checks, 'githubchecks.app-id' is not configured for '%s'", pipeline.GetId())
+		return nil
+	}
+
+	runs, err := r.createCheckRuns(cfg, pipeline, withLogs)
+	if err != nil {
+		return err
+	}
+	client, err := r.createClient(ctx, cfg, pipeline)
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if err := r.upsertCheckRun(ctx, client, pipeline, run, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertCheckRun creates a Check Run once, then updates it only when its content has changed
+func (r *Receiver) upsertCheckRun(ctx context.Context, client *apiClient, pipeline contract.PipelineInfo, run checkRun,
+	log *logging.InternalLogger) error {
+
+	storeKey := r.GetImplementationName() + "/" + run.Name
+	content, _ := json.Marshal(run)
+	if run.Status == "completed" {
+		completedAt := run.completedAt
+		run.CompletedAt = &completedAt
+	}
+	repository := pipeline.GetSCMContext().GetNameWithOrg()
+
+	// 1. Create a new Check Run
+	checkRunId := r.sc.Store.GetMessageReference(pipeline, storeKey)
+	if checkRunId == "" {
+		createdId, err := client.createCheckRun(ctx, repository, run)
+		if err != nil {
+			return errors.Wrapf(err, "cannot create Check Run '%s'", run.Name)
+		}
+		r.sc.Store.RecordMessageReference(pipeline, storeKey, createdId, string(content))
+		return nil
+	}
+
+	// 2. Update the existing Check Run, if anything changed
+	if r.sc.Store.IsMessageUpToDate(pipeline, storeKey, string(content)) {
+		log.Debugf("Skipping Check Run update, '%s' already up-to-date for '%s'", run.Name, pipeline.GetId())
+		return nil
+	}
+	if err := client.updateCheckRun(ctx, repository, checkRunId, run); err != nil {
+		return errors.Wrapf(err, "cannot update Check Run '%s'", run.Name)
+	}
+	r.sc.Store.RecordMessageReference(pipeline, storeKey, checkRunId, string(content))
+	return nil
+}
+
+// createCheckRuns builds a single Check Run for the Pipeline, or one per stage in "stage" mode
+func (r *Receiver) createCheckRuns(cfg config.Data, pipeline contract.PipelineInfo, withLogs bool) ([]checkRun, error) {
+	details := output{}
+	if withLogs && pipeline.GetStatus().IsFinished() {
+		details = createDetails(cfg, pipeline)
+	}
+
+	mode := cfg.GetOrDefault("mode", ModePipeline)
+	if mode == ModePipeline {
+		run, err := r.createCheckRun(cfg, pipeline, nil, details)
+		if err != nil {
+			return nil, err
+		}
+		return []checkRun{run}, nil
+	}
+	if mode != ModeStage {
+		return nil, errors.Errorf("invalid 'githubchecks.mode' value: '%s', expected '%s' or '%s'", mode, ModePipeline, ModeStage)
+	}
+
+	runs := make([]checkRun, 0, len(pipeline.GetStages()))
+	for _, stage := range pipeline.GetStages() {
+		stageDetails := output{}
+		// logs are not split per stage, so they are attached where they are needed the most
+		if stage.Status.IsErroredOrFailed() {
+			stageDetails = details
+		}
+		run, err := r.createCheckRun(cfg, pipeline, &stage, stageDetails)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func (r *Receiver) createCheckRun(cfg config.Data, pipeline contract.PipelineInfo, stage *contract.PipelineStage, details output) (checkRun, error) {
+	variables := map[string]interface{}{"stage": stage}
+	nameTemplate := cfg.GetOrDefault("name", defaultName)
+	status := pipeline.GetStatus()
+	startedAt := pipeline.GetDateStarted()
+	completedAt := latestStageFinish(pipeline.GetStages())
+	if stage != nil {
+		nameTemplate = cfg.GetOrDefault("stage-name", defaultStageName)
+		status = stage.Status
+		startedAt = stage.StartedAt
+		completedAt = stage.FinishedAt
+	}
+
+	name, err := templating.TemplateChatMessage(nameTemplate, "githubchecks-name", pipeline, variables)
+	if err != nil {
+		return checkRun{}, errors.Wrap(err, "cannot create a Check Run name from template")
+	}
+	summary, err := templating.TemplateChatMessage(cfg.GetOrDefault("summary", defaultSummary), "githubchecks-summary", pipeline, variables)
+	if err != nil {
+		return checkRun{}, errors.Wrap(err, "cannot create a Check Run summary from template")
+	}
+	runStatus, conclusion := translateStatus(status)
+	if completedAt.IsZero() {
+		completedAt = time.Now()
+	}
+
+	run := checkRun{
+		Name:        strings.TrimSpace(name),
+		HeadSha:     pipeline.GetSCMContext().Commit,
+		DetailsUrl:  pipeline.GetDashboardUrl(),
+		ExternalId:  pipeline.GetId(),
+		Status:      runStatus,
+		Conclusion:  conclusion,
+		completedAt: completedAt,
+		Output: &output{
+			Title:       fmt.Sprintf("%s %s", strings.TrimSpace(name), status.AsHumanReadableDescription()),
+			Summary:     strings.TrimSpace(summary),
+			Text:        details.Text,
+			Annotations: details.Annotations,
+		},
+	}
+	if !startedAt.IsZero() {
+		run.StartedAt = &startedAt
+	}
+	return run, nil
+}
+
+// createDetails collects the logs excerpt and annotations. Logs are fetched only once the Pipeline finished,
+// as it is an expensive operation
+func createDetails(cfg config.Data, pipeline contract.PipelineInfo) output {
+	logs := pipeline.GetLogs()
+	if logs == "" {
+		return output{}
+	}
+	logsMaxLength, _ := strconv.Atoi(cfg.GetOrDefault("logs-max-length", "60000"))
+	details := output{Text: "```\n" + templating.Tail(logs, logsMaxLength) + "\n```"}
+	if pipeline.GetStatus().IsErroredOrFailed() {
+		details.Annotations = ParseAnnotations(logs, cfg.Get("annotations-strip-prefix"), MaxAnnotationsPerRequest)
+	}
+	return details
+}
+
+func (r *Receiver) createClient(ctx context.Context, cfg config.Data, pipeline contract.PipelineInfo) (*apiClient, error) {
+	// "githubchecks.private-key" as plaintext, or a `kind: Secret` referenced by "githubchecks.private-key-secret-name" and "githubchecks.private-key-secret-key"
+	privateKey, err := r.sc.Config.FetchFromFieldOrSecret(ctx, &cfg, pipeline.GetNamespace(), "private-key", "private-key-secret-key", "private-key-secret-name")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot fetch GitHub App private key neither from 'githubchecks.private-key' as plaintext neither from a `kind: Secret` referenced in 'githubchecks.private-key-secret-name'")
+	}
+	if privateKey == "" {
+		return nil, errors.New("cannot fetch GitHub App private key neither from 'githubchecks.private-key' as plaintext neither from a `kind: Secret` referenced in 'githubchecks.private-key-secret-name'")
+	}
+
+	scmCtx := pipeline.GetSCMContext()
+	apiUrl := strings.TrimRight(cfg.GetOrDefault("api-url", githubapp.ApiUrlFromRepository(scmCtx.RepoHttpsUrl)), "/")
+	app, err := githubapp.NewApp(cfg.Get("app-id"), privateKey, apiUrl, r.sc.Store)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create GitHub App client")
+	}
+
+	var token string
+	if installationId := cfg.Get("installation-id"); installationId != "" {
+		token, err = app.GetInstallationToken(ctx, installationId)
+	} else {
+		token, err = app.GetRepositoryToken(ctx, scmCtx.OrganizationName, scmCtx.RepositoryName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newApiClient(apiUrl, token), nil
+}
+
+func (r *Receiver) CanHandle(name string) bool {
+	return name == r.GetImplementationName()
+}
+
+func (r *Receiver) GetImplementationName() string {
+	return "githubchecks"
+}
+
+// translateStatus returns the Check Run status and conclusion
+func translateStatus(status contract.Status) (string, string) {
+	switch status {
+	case contract.PipelineRunning:
+		return "in_progress", ""
+	case contract.PipelineSucceeded:
+		return "completed", "success"
+	case contract.PipelineFailed, contract.PipelineErrored:
+		return "completed", "failure"
+	case contract.PipelineCancelled:
+		return "completed", "cancelled"
+	case contract.PipelineSkipped:
+		return "completed", "skipped"
+	default:
+		return "queued", ""
+	}
+}
+
+// latestStageFinish returns the latest stage finish time, zero when no stage has it
+func latestStageFinish(stages []contract.PipelineStage) time.Time {
+	latest := time.Time{}
+	for _, stage := range stages {
+		if stage.FinishedAt.After(latest) {
+			latest = stage.FinishedAt
+		}
+	}
+	return latest
+}
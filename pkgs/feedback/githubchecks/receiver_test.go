@@ -0,0 +1,202 @@
+package githubchecks_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/githubchecks"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type apiCall struct {
+	method        string
+	path          string
+	authorization string
+	body          map[string]interface{}
+}
+
+// fakeGitHub is a local HTTP server pretending to be the GitHub App and Checks API
+type fakeGitHub struct {
+	sync.Mutex
+	server      *httptest.Server
+	calls       []apiCall
+	checkRunNum int
+}
+
+func newFakeGitHub() *fakeGitHub {
+	f := &fakeGitHub{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		f.Lock()
+		defer f.Unlock()
+		f.calls = append(f.calls, apiCall{method: r.Method, path: r.URL.Path, authorization: r.Header.Get("Authorization"), body: body})
+
+		switch {
+		case r.URL.Path == "/repos/kube-cicd/bakery/installation":
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		case r.URL.Path == "/app/installations/42/access_tokens":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"token":      "ghs_bakery",
+				"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/kube-cicd/bakery/check-runs":
+			f.checkRunNum++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": 1000 + f.checkRunNum})
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/kube-cicd/bakery/check-runs/"):
+			_, _ = w.Write([]byte(`{"id": 1001}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return f
+}
+
+// checkRunCalls returns calls to the Checks API, skipping the authentication
+func (f *fakeGitHub) checkRunCalls() []apiCall {
+	f.Lock()
+	defer f.Unlock()
+	calls := make([]apiCall, 0)
+	for _, call := range f.calls {
+		if strings.Contains(call.path, "/check-runs") {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func createPrivateKey() string {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func createPipeline(status contract.Status, stages []contract.PipelineStage) contract.PipelineInfo {
+	globalCfg := config.NewData("global", map[string]string{}, &fake.NullValidator{}, logging.CreateLogger(true))
+	return *contract.NewPipelineInfo(
+		contract.JobContext{Commit: "76ea7c7", RepoHttpsUrl: "https://github.com/kube-cicd/bakery", OrganizationName: "kube-cicd", RepositoryName: "bakery"},
+		"team-1",
+		"bread-pipeline",
+		"bread-pipeline-abc",
+		time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		stages,
+		labels.Set{},
+		labels.Set{},
+		&globalCfg,
+		contract.PipelineInfoWithLogsCollector(func() string {
+			return "go test ./...\n--- FAIL: TestBake (0.00s)\n    oven_test.go:21: the bread is burnt\nFAIL\n"
+		}),
+	)
+}
+
+func createReceiver(apiUrl string, cfg map[string]string) *githubchecks.Receiver {
+	logger := logging.CreateLogger(true)
+	data := map[string]string{
+		"api-url":     apiUrl,
+		"app-id":      "161",
+		"private-key": createPrivateKey(),
+	}
+	for key, value := range cfg {
+		data[key] = value
+	}
+	receiver := &githubchecks.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.FieldConfigurationProvider{ConfigurationProvider: fake.ConfigurationProvider{Contextual: config.NewData("githubchecks", data, &fake.NullValidator{}, logger)}},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+		Store:        &store.Operator{Store: store.NewMemory()},
+	})
+	return receiver
+}
+
+func TestReceiver_PipelineCheckRunLifecycle(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	receiver := createReceiver(github.server.URL, map[string]string{})
+	log := logging.CreateLogger(true)
+
+	running := createPipeline(contract.PipelineRunning, []contract.PipelineStage{{Name: "bake", Status: contract.PipelineRunning}})
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), running, log))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), running, log)) // nothing changed, nothing sent
+
+	failed := createPipeline(contract.PipelineFailed, []contract.PipelineStage{{Name: "bake", Status: contract.PipelineFailed}})
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), failed, log))
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), failed, log))
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), failed, log)) // retried, but already sent
+
+	calls := github.checkRunCalls()
+	assert.Len(t, calls, 3)
+
+	// created
+	assert.Equal(t, "POST /repos/kube-cicd/bakery/check-runs", calls[0].method+" "+calls[0].path)
+	assert.Equal(t, "Bearer ghs_bakery", calls[0].authorization)
+	assert.Equal(t, "team-1/bread-pipeline", calls[0].body["name"])
+	assert.Equal(t, "76ea7c7", calls[0].body["head_sha"])
+	assert.Equal(t, "in_progress", calls[0].body["status"])
+	assert.Equal(t, "2026-03-01T12:00:00Z", calls[0].body["started_at"])
+
+	// completed
+	assert.Equal(t, "PATCH /repos/kube-cicd/bakery/check-runs/1001", calls[1].method+" "+calls[1].path)
+	assert.Equal(t, "completed", calls[1].body["status"])
+	assert.Equal(t, "failure", calls[1].body["conclusion"])
+	assert.NotEmpty(t, calls[1].body["completed_at"])
+	assert.Nil(t, calls[1].body["head_sha"])
+
+	// logs and annotations
+	finalOutput := calls[2].body["output"].(map[string]interface{})
+	assert.Equal(t, "team-1/bread-pipeline failed", finalOutput["title"])
+	assert.Contains(t, finalOutput["summary"], "| bake | failed |")
+	assert.Contains(t, finalOutput["text"], "the bread is burnt")
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"path": "oven_test.go", "start_line": float64(21), "end_line": float64(21),
+		"annotation_level": "failure", "message": "the bread is burnt",
+	}}, finalOutput["annotations"])
+}
+
+func TestReceiver_CheckRunPerStage(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	receiver := createReceiver(github.server.URL, map[string]string{"mode": "stage", "stage-name": "ci/{{ .stage.Name }}"})
+
+	pipeline := createPipeline(contract.PipelineFailed, []contract.PipelineStage{
+		{Name: "knead", Status: contract.PipelineSucceeded},
+		{Name: "bake", Status: contract.PipelineFailed},
+	})
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), pipeline, logging.CreateLogger(true)))
+
+	calls := github.checkRunCalls()
+	assert.Len(t, calls, 2)
+	assert.Equal(t, "ci/knead", calls[0].body["name"])
+	assert.Equal(t, "success", calls[0].body["conclusion"])
+	assert.Nil(t, calls[0].body["output"].(map[string]interface{})["text"])
+	assert.Equal(t, "ci/bake", calls[1].body["name"])
+	assert.Equal(t, "failure", calls[1].body["conclusion"])
+	assert.Contains(t, calls[1].body["output"].(map[string]interface{})["text"], "the bread is burnt")
+}
+
+func TestReceiver_SkipsWhenNotConfigured(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	receiver := createReceiver(github.server.URL, map[string]string{"app-id": ""})
+
+	pipeline := createPipeline(contract.PipelineRunning, []contract.PipelineStage{})
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), pipeline, logging.CreateLogger(true)))
+	assert.Empty(t, github.calls)
+}
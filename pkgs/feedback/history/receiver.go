@@ -0,0 +1,248 @@
+package history
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/apis/pipelinesfeedback.keskad.pl/v1alpha1"
+	v1alpha1client "github.com/kube-cicd/pipelines-feedback-core/pkgs/client/clientset/versioned"
+	pipelinesfeedbackv1alpha1 "github.com/kube-cicd/pipelines-feedback-core/pkgs/client/clientset/versioned/typed/pipelinesfeedback.keskad.pl/v1alpha1"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Receiver writes a `kind: PipelineFeedbackRecord` per Pipeline execution, so the history is kept after the Pipeline
+// was garbage-collected. It can wrap other Receiver (Delegate), then the record is written after the feedback was sent
+type Receiver struct {
+	// Delegate is an optional Receiver that sends the actual feedback
+	Delegate feedback.Receiver
+
+	// Client is created from the kubeconfig, when not provided
+	Client pipelinesfeedbackv1alpha1.PipelinesfeedbackV1alpha1Interface
+
+	sc *wiring.ServiceContext
+}
+
+func (h *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing History Receiver")
+	h.sc = sc
+
+	// register configuration options
+	h.sc.ConfigSchema.Add(config.Schema{
+		Name: "history",
+		AllowedFields: []string{
+			"enabled",
+			"retention",
+			"max-records",
+		},
+	})
+
+	if h.Client == nil {
+		client, err := v1alpha1client.NewForConfig(sc.KubeConfig)
+		if err != nil {
+			return errors.Wrap(err, "cannot initialize PipelineFeedbackRecord client")
+		}
+		h.Client = client.PipelinesfeedbackV1alpha1()
+	}
+	if initializable, ok := h.Delegate.(wiring.WithInitialization); ok {
+		if err := initializable.InitializeWithContext(sc); err != nil {
+			return errors.Wrapf(err, "cannot initialize '%s' Receiver", h.Delegate.GetImplementationName())
+		}
+	}
+	return nil
+}
+
+func (h *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	if h.Delegate == nil {
+		return nil
+	}
+	return h.Delegate.WhenCreated(ctx, pipeline, log)
+}
+
+func (h *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	if h.Delegate == nil {
+		return nil
+	}
+	return h.Delegate.WhenStarted(ctx, pipeline, log)
+}
+
+// UpdateProgress is called on every state change, so the record follows the Pipeline.
+// It is called also after WhenFinished, then the finish time must be kept
+func (h *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	if h.Delegate != nil {
+		if err := h.Delegate.UpdateProgress(ctx, pipeline, log); err != nil {
+			return err
+		}
+	}
+	finishedAt := time.Time{}
+	if pipeline.GetStatus().IsFinished() {
+		finishedAt = latestStageFinish(pipeline, time.Now())
+	}
+	if err := h.record(ctx, pipeline, finishedAt, log); err != nil {
+		// not critical, the record is updated on the next change. Returning the error would repeat the delegated feedback
+		log.Warningf("Cannot record the Pipeline in PipelineFeedbackRecord: %s", err.Error())
+	}
+	return nil
+}
+
+// WhenFinished writes the final state and removes records that are out of the retention
+func (h *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	if h.Delegate != nil {
+		if err := h.Delegate.WhenFinished(ctx, pipeline, log); err != nil {
+			return err
+		}
+	}
+	if err := h.record(ctx, pipeline, latestStageFinish(pipeline, time.Now()), log); err != nil {
+		// returning the error would send the delegated feedback (e.g. a summary comment) again on retry
+		log.Warningf("Cannot record the finished Pipeline in PipelineFeedbackRecord: %s", err.Error())
+		return nil
+	}
+	if err := h.applyRetention(ctx, pipeline, log); err != nil {
+		// not critical, next finished Pipeline will retry
+		log.Warningf("Cannot clean up old PipelineFeedbackRecords: %s", err.Error())
+	}
+	return nil
+}
+
+// record creates or updates the PipelineFeedbackRecord
+func (h *Receiver) record(ctx context.Context, pipeline contract.PipelineInfo, finishedAt time.Time, log *logging.InternalLogger) error {
+	cfg := h.sc.Config.FetchContextual("history", pipeline.GetNamespace(), pipeline)
+	if cfg.GetOrDefault("enabled", "true") != "true" {
+		return nil
+	}
+
+	records := h.Client.PipelineFeedbackRecords(pipeline.GetNamespace())
+	name := CreateRecordName(pipeline)
+	spec := NewRecordSpec(pipeline, finishedAt)
+	recordLabels := map[string]string{
+		PipelineLabel: truncate(spec.Pipeline.Name, 63),
+		StatusLabel:   string(spec.Status),
+	}
+
+	existing, err := records.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		spec.Deliveries = collectDeliveries(h.sc.Store, pipeline)
+		_, createErr := records.Create(ctx, &v1alpha1.PipelineFeedbackRecord{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: pipeline.GetNamespace(), Labels: recordLabels},
+			Spec:       spec,
+		}, metav1.CreateOptions{})
+		if createErr != nil {
+			return errors.Wrapf(createErr, "cannot create PipelineFeedbackRecord '%s'", name)
+		}
+		log.Debugf("Created PipelineFeedbackRecord '%s'", name)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot fetch PipelineFeedbackRecord '%s'", name)
+	}
+
+	spec.Deliveries = mergeDeliveries(existing.Spec.Deliveries, collectDeliveries(h.sc.Store, pipeline))
+	if existing.Labels == nil {
+		existing.Labels = map[string]string{}
+	}
+	for key, value := range recordLabels {
+		existing.Labels[key] = value
+	}
+	existing.Spec = spec
+	if _, err := records.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "cannot update PipelineFeedbackRecord '%s'", name)
+	}
+	log.Debugf("Updated PipelineFeedbackRecord '%s'", name)
+	return nil
+}
+
+// applyRetention removes records of the same Pipeline that are older than "history.retention",
+// or exceed "history.max-records" (the newest are kept)
+func (h *Receiver) applyRetention(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := h.sc.Config.FetchContextual("history", pipeline.GetNamespace(), pipeline)
+	if cfg.GetOrDefault("enabled", "true") != "true" {
+		return nil
+	}
+	retention, err := time.ParseDuration(cfg.GetOrDefault("retention", "720h"))
+	if err != nil {
+		return errors.Wrap(err, "invalid 'history.retention'")
+	}
+	maxRecords, err := strconv.Atoi(cfg.GetOrDefault("max-records", "100"))
+	if err != nil {
+		return errors.Wrap(err, "invalid 'history.max-records'")
+	}
+
+	records := h.Client.PipelineFeedbackRecords(pipeline.GetNamespace())
+	list, err := records.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{PipelineLabel: truncate(getPipelineName(pipeline), 63)}).String(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot list PipelineFeedbackRecords")
+	}
+
+	// newest first, the current record is not a subject of deletion and takes the first place
+	currentName := CreateRecordName(pipeline)
+	items := make([]v1alpha1.PipelineFeedbackRecord, 0, len(list.Items))
+	for _, item := range list.Items {
+		if item.Name != currentName {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return recordTime(items[i]).After(recordTime(items[j]))
+	})
+	deadline := time.Now().Add(-retention)
+	for num, item := range items {
+		isExpired := retention > 0 && recordTime(item).Before(deadline)
+		isOverLimit := maxRecords > 0 && num+1 >= maxRecords
+		if !isExpired && !isOverLimit {
+			continue
+		}
+		log.Debugf("Deleting PipelineFeedbackRecord '%s'", item.Name)
+		if err := records.Delete(ctx, item.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "cannot delete PipelineFeedbackRecord '%s'", item.Name)
+		}
+	}
+	return nil
+}
+
+// Close releases resources of the Delegate
+func (h *Receiver) Close() {
+	if closer, ok := h.Delegate.(wiring.WithClosing); ok {
+		closer.Close()
+	}
+}
+
+func (h *Receiver) CanHandle(name string) bool {
+	return name == h.GetImplementationName()
+}
+
+func (h *Receiver) GetImplementationName() string {
+	return "history"
+}
+
+// recordTime is the time the Pipeline finished, or when the record was created for unfinished Pipelines
+func recordTime(record v1alpha1.PipelineFeedbackRecord) time.Time {
+	if record.Spec.FinishedAt != nil {
+		return record.Spec.FinishedAt.Time
+	}
+	return record.CreationTimestamp.Time
+}
+
+// latestStageFinish returns the latest stage finish time, or the fallback when no stage has it
+func latestStageFinish(pipeline contract.PipelineInfo, fallback time.Time) time.Time {
+	latest := time.Time{}
+	for _, stage := range pipeline.GetStages() {
+		if stage.FinishedAt.After(latest) {
+			latest = stage.FinishedAt
+		}
+	}
+	if latest.IsZero() {
+		return fallback
+	}
+	return latest
+}
@@ -0,0 +1,203 @@
+package history_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/apis/pipelinesfeedback.keskad.pl/v1alpha1"
+	clientfake "github.com/kube-cicd/pipelines-feedback-core/pkgs/client/clientset/versioned/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/history"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var started = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// failingReceiver is a Delegate that always fails
+type failingReceiver struct{}
+
+func (f *failingReceiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return errors.New("SCM is down")
+}
+func (f *failingReceiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+func (f *failingReceiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+func (f *failingReceiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return errors.New("SCM is down")
+}
+
+// countingReceiver is a Delegate that counts the finished events
+type countingReceiver struct {
+	failingReceiver
+	finished int
+}
+
+func (c *countingReceiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+func (c *countingReceiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	c.finished++
+	return nil
+}
+
+func (f *failingReceiver) CanHandle(name string) bool    { return false }
+func (f *failingReceiver) GetImplementationName() string { return "failing" }
+
+func createPipeline(instanceName string, status contract.Status) contract.PipelineInfo {
+	globalCfg := config.NewData("global", map[string]string{}, &fake.NullValidator{}, logging.CreateLogger(true))
+	return *contract.NewPipelineInfo(
+		contract.JobContext{Commit: "76ea7c7", RepoHttpsUrl: "https://github.com/kube-cicd/bakery", PrId: "161"},
+		"team-1",
+		"bread-pipeline",
+		instanceName,
+		started,
+		[]contract.PipelineStage{
+			{Name: "knead", Status: contract.PipelineSucceeded, StartedAt: started, FinishedAt: started.Add(time.Minute)},
+			{Name: "bake", Status: status, StartedAt: started.Add(time.Minute)},
+		},
+		labels.Set{},
+		labels.Set{},
+		&globalCfg,
+	)
+}
+
+func createReceiver(cfg map[string]string, operator *store.Operator, objects ...*v1alpha1.PipelineFeedbackRecord) (*history.Receiver, *clientfake.Clientset) {
+	logger := logging.CreateLogger(true)
+	client := clientfake.NewSimpleClientset()
+	for _, obj := range objects {
+		_, _ = client.PipelinesfeedbackV1alpha1().PipelineFeedbackRecords(obj.Namespace).Create(context.TODO(), obj, metav1.CreateOptions{})
+	}
+	receiver := &history.Receiver{Client: client.PipelinesfeedbackV1alpha1()}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.ConfigurationProvider{Contextual: config.NewData("history", cfg, &fake.NullValidator{}, logger)},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+		Store:        operator,
+	})
+	return receiver, client
+}
+
+func TestReceiver_RecordsPipelineExecution(t *testing.T) {
+	operator := &store.Operator{Store: store.NewMemory()}
+	receiver, client := createReceiver(map[string]string{}, operator)
+	log := logging.CreateLogger(true)
+	records := client.PipelinesfeedbackV1alpha1().PipelineFeedbackRecords("team-1")
+
+	running := createPipeline("bread-pipeline-abc", contract.PipelineRunning)
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), running, log))
+
+	record, err := records.Get(context.TODO(), history.CreateRecordName(running), metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, contract.PipelineRunning, record.Spec.Status)
+	assert.Nil(t, record.Spec.FinishedAt)
+	assert.Equal(t, "bread-pipeline", record.Labels[history.PipelineLabel])
+
+	// a comment was posted in the meantime
+	operator.RecordInfoAboutLastComment(running, "4815162342")
+	finished := createPipeline("bread-pipeline-abc", contract.PipelineFailed)
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), finished, log))
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), finished, log))
+
+	record, err = records.Get(context.TODO(), history.CreateRecordName(finished), metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, contract.PipelineFailed, record.Spec.Status)
+	assert.Equal(t, "failed", record.Labels[history.StatusLabel])
+	assert.Equal(t, "team-1/bread-pipeline/bread-pipeline-abc", record.Spec.Pipeline.Id)
+	assert.Equal(t, started, record.Spec.StartedAt.Time.UTC())
+	assert.Equal(t, started.Add(time.Minute), record.Spec.FinishedAt.Time.UTC())
+	assert.Equal(t, "76ea7c7", record.Spec.Scm.Commit)
+	assert.Equal(t, "161", record.Spec.Scm.PrId)
+	assert.Len(t, record.Spec.Stages, 2)
+	assert.Equal(t, []v1alpha1.RecordDelivery{{Receiver: "jxscm", Kind: "comment", Id: "4815162342"}}, record.Spec.Deliveries)
+
+	// the Pipeline is reconciled again after it was finished
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), finished, log))
+	record, err = records.Get(context.TODO(), history.CreateRecordName(finished), metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.NotNil(t, record.Spec.FinishedAt)
+	assert.Equal(t, started.Add(time.Minute), record.Spec.FinishedAt.Time.UTC())
+}
+
+func TestReceiver_AppliesRetention(t *testing.T) {
+	createRecord := func(name string, finishedAt time.Time) *v1alpha1.PipelineFeedbackRecord {
+		finished := metav1.NewTime(finishedAt)
+		return &v1alpha1.PipelineFeedbackRecord{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-1", Labels: map[string]string{history.PipelineLabel: "bread-pipeline"}},
+			Spec:       v1alpha1.RecordSpec{FinishedAt: &finished},
+		}
+	}
+	otherPipeline := createRecord("croissant-pipeline-1", time.Now().Add(-1000*time.Hour))
+	otherPipeline.Labels[history.PipelineLabel] = "croissant-pipeline"
+
+	receiver, client := createReceiver(map[string]string{"retention": "48h", "max-records": "3"}, &store.Operator{Store: store.NewMemory()},
+		createRecord("bread-pipeline-expired", time.Now().Add(-72*time.Hour)),
+		createRecord("bread-pipeline-1", time.Now().Add(-4*time.Hour)),
+		createRecord("bread-pipeline-2", time.Now().Add(-3*time.Hour)),
+		createRecord("bread-pipeline-3", time.Now().Add(-2*time.Hour)),
+		otherPipeline,
+	)
+	pipeline := createPipeline("bread-pipeline-abc", contract.PipelineSucceeded)
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), pipeline, logging.CreateLogger(true)))
+
+	list, _ := client.PipelinesfeedbackV1alpha1().PipelineFeedbackRecords("team-1").List(context.TODO(), metav1.ListOptions{})
+	names := make([]string, 0)
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	assert.ElementsMatch(t, []string{history.CreateRecordName(pipeline), "bread-pipeline-2", "bread-pipeline-3", "croissant-pipeline-1"}, names)
+}
+
+func TestReceiver_DoesNotRecordWhenDelegateFailed(t *testing.T) {
+	receiver, client := createReceiver(map[string]string{}, &store.Operator{Store: store.NewMemory()})
+	receiver.Delegate = &failingReceiver{}
+	pipeline := createPipeline("bread-pipeline-abc", contract.PipelineSucceeded)
+
+	err := receiver.WhenFinished(context.TODO(), pipeline, logging.CreateLogger(true))
+
+	assert.NotNil(t, err)
+	list, _ := client.PipelinesfeedbackV1alpha1().PipelineFeedbackRecords("team-1").List(context.TODO(), metav1.ListOptions{})
+	assert.Empty(t, list.Items)
+}
+
+func TestReceiver_FailedRecordDoesNotRepeatDelegatedFeedback(t *testing.T) {
+	receiver, client := createReceiver(map[string]string{}, &store.Operator{Store: store.NewMemory()})
+	delegate := &countingReceiver{}
+	receiver.Delegate = delegate
+	client.PrependReactor("create", "pipelinefeedbackrecords", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("the server could not find the requested resource")
+	})
+	pipeline := createPipeline("bread-pipeline-abc", contract.PipelineSucceeded)
+	log := logging.CreateLogger(true)
+
+	// the controller retries the event only when an error is returned
+	for attempt := 0; attempt < 3; attempt++ {
+		if err := receiver.WhenFinished(context.TODO(), pipeline, log); err == nil {
+			break
+		}
+	}
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), pipeline, log))
+
+	assert.Equal(t, 1, delegate.finished)
+}
+
+func TestCreateRecordName_IsStableAndUniquePerExecution(t *testing.T) {
+	first := history.CreateRecordName(createPipeline("bread-pipeline-abc", contract.PipelineRunning))
+
+	assert.Equal(t, first, history.CreateRecordName(createPipeline("bread-pipeline-abc", contract.PipelineSucceeded)))
+	assert.NotEqual(t, first, history.CreateRecordName(createPipeline("bread-pipeline-def", contract.PipelineSucceeded)))
+	assert.Regexp(t, "^bread-pipeline-[0-9a-f]{10}$", first)
+}
@@ -0,0 +1,116 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	pipelinesfeedbackkeskadpl "github.com/kube-cicd/pipelines-feedback-core/pkgs/apis/pipelinesfeedback.keskad.pl"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/apis/pipelinesfeedback.keskad.pl/v1alpha1"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	PipelineLabel = pipelinesfeedbackkeskadpl.GroupName + "/pipeline"
+	StatusLabel   = pipelinesfeedbackkeskadpl.GroupName + "/status"
+)
+
+// CreateRecordName returns a stable name of the record for a Pipeline execution. Pipeline name is kept for readability,
+// the hash makes the name unique across executions
+func CreateRecordName(pipeline contract.PipelineInfo) string {
+	sum := sha256.Sum256([]byte(pipeline.GetId()))
+	return truncate(getPipelineName(pipeline), 52) + "-" + hex.EncodeToString(sum[:])[:10]
+}
+
+// NewRecordSpec translates the PipelineInfo into .spec of the PipelineFeedbackRecord
+func NewRecordSpec(pipeline contract.PipelineInfo, finishedAt time.Time) v1alpha1.RecordSpec {
+	stages := make([]v1alpha1.RecordStage, 0, len(pipeline.GetStages()))
+	for _, stage := range pipeline.GetStages() {
+		stages = append(stages, v1alpha1.RecordStage{
+			Name:       stage.Name,
+			Status:     stage.Status,
+			StartedAt:  toMetaTime(stage.StartedAt),
+			FinishedAt: toMetaTime(stage.FinishedAt),
+		})
+	}
+	scmCtx := pipeline.GetSCMContext()
+
+	return v1alpha1.RecordSpec{
+		Pipeline: v1alpha1.RecordPipeline{
+			Id:           pipeline.GetId(),
+			Name:         getPipelineName(pipeline),
+			InstanceName: pipeline.GetInstanceName(),
+		},
+		Status:       pipeline.GetStatus(),
+		Description:  pipeline.GetStatus().AsHumanReadableDescription(),
+		DashboardUrl: pipeline.GetDashboardUrl(),
+		StartedAt:    toMetaTime(pipeline.GetDateStarted()),
+		FinishedAt:   toMetaTime(finishedAt),
+		Stages:       stages,
+		Scm: v1alpha1.RecordScm{
+			Repository:   scmCtx.RepoHttpsUrl,
+			Commit:       scmCtx.Commit,
+			Reference:    scmCtx.Reference,
+			PrId:         scmCtx.PrId,
+			SourceBranch: scmCtx.SourceBranch,
+			TargetBranch: scmCtx.TargetBranch,
+			Tag:          scmCtx.Tag,
+			TechnicalJob: scmCtx.TechnicalJob,
+		},
+	}
+}
+
+// collectDeliveries looks up the store for identifiers of comments and messages the receivers sent for the Pipeline
+func collectDeliveries(operator *store.Operator, pipeline contract.PipelineInfo) []v1alpha1.RecordDelivery {
+	deliveries := make([]v1alpha1.RecordDelivery, 0)
+	if operator == nil {
+		return deliveries
+	}
+	if commentId := operator.GetStatusPRCommentId(pipeline); commentId != "" {
+		deliveries = append(deliveries, v1alpha1.RecordDelivery{Receiver: "jxscm", Kind: "comment", Id: commentId})
+	}
+	if messageRef := operator.GetMessageReference(pipeline, "slack"); messageRef != "" {
+		deliveries = append(deliveries, v1alpha1.RecordDelivery{Receiver: "slack", Kind: "message", Id: messageRef})
+	}
+	return deliveries
+}
+
+// mergeDeliveries keeps already recorded deliveries, as the store entries may expire before the Pipeline finishes
+func mergeDeliveries(existing []v1alpha1.RecordDelivery, found []v1alpha1.RecordDelivery) []v1alpha1.RecordDelivery {
+	merged := append([]v1alpha1.RecordDelivery{}, existing...)
+	for _, delivery := range found {
+		isKnown := false
+		for _, known := range merged {
+			if known == delivery {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			merged = append(merged, delivery)
+		}
+	}
+	return merged
+}
+
+func getPipelineName(pipeline contract.PipelineInfo) string {
+	return strings.TrimPrefix(pipeline.GetName(), pipeline.GetNamespace()+"/")
+}
+
+func toMetaTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	converted := metav1.NewTime(t)
+	return &converted
+}
+
+func truncate(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	return strings.TrimRight(text[:maxLength], "-.")
+}
@@ -0,0 +1,117 @@
+package jxscm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
+	"github.com/pkg/errors"
+)
+
+const progressCommentModeAggregated = "aggregated"
+
+const defaultAggregatedCommentHeader = `
+:rocket: Pipelines
+--------------------------------------
+`
+
+const defaultAggregatedCommentSection = `
+#### {{ if .pipeline.GetStatus.IsNotStarted }}:timer:{{ else if .pipeline.GetStatus.IsRunning }}:hourglass_flowing_sand:{{ else if .pipeline.GetStatus.IsErroredOrFailed }}:x:{{ else if .pipeline.GetStatus.IsSucceeded }}:white_check_mark:{{ end }} {{ .pipeline.GetName }} ({{ .pipeline.GetInstanceName }}) {{ .pipeline.GetStatus.AsHumanReadableDescription }}
+
+| Stage | Status |
+|-------|--------|
+{{- range $stage := .pipeline.GetStages }}
+| {{ $stage.Name }} |  {{ if $stage.Status.IsSkipped }}:arrow_lower_left: Skipped{{ else if $stage.Status.IsNotStarted }}Pending{{ else if $stage.Status.IsRunning }}:hourglass_flowing_sand:{{ else if $stage.Status.IsErroredOrFailed }}:x:{{ else if $stage.Status.IsSucceeded }}:white_check_mark:{{ else }}{{ $stage.Status.AsHumanReadableDescription }}{{ end }}  |
+{{- end }}
+
+{{ if .pipeline.GetDashboardUrl }}- [Open in dashboard]({{ .pipeline.GetDashboardUrl }}){{ end }}
+`
+
+// isAggregatedCommentMode tells if all Pipelines of a Pull Request share a single progress comment
+func isAggregatedCommentMode(cfg config.Data) bool {
+	return cfg.GetOrDefault("progress-comment-mode", "per-pipeline") == progressCommentModeAggregated
+}
+
+// updateAggregatedPRComment keeps a single PR comment with a section per Pipeline. The section of the Pipeline is merged
+// into sections of other Pipelines kept in the store, then the whole comment is rendered again.
+// A rerun of a Pipeline replaces its previous section, as sections are identified by Pipeline name, not by its run.
+// Sections of Pipelines that ran for a previous commit are removed, a Pipeline of a previous commit that is reconciled late
+// does not get its section back
+func (jx *Receiver) updateAggregatedPRComment(ctx context.Context, cfg config.Data, client *scm.Client, pipeline contract.PipelineInfo) error {
+	scmCtx := pipeline.GetSCMContext()
+	prId, _ := strconv.Atoi(scmCtx.PrId)
+	markingPart := "(pfc-pr=" + scmCtx.PrId + "/updateAggregatedPRComment)" // we identify a comment by this marking
+
+	section, tplErr := templating.TemplateProgressComment(cfg.GetOrDefault("aggregated-comment-section", defaultAggregatedCommentSection), pipeline, markingPart)
+	if tplErr != nil {
+		return errors.Wrap(tplErr, "cannot create a comment section from template")
+	}
+	header, tplErr := templating.TemplateProgressComment(cfg.GetOrDefault("aggregated-comment-header", defaultAggregatedCommentHeader), pipeline, markingPart)
+	if tplErr != nil {
+		return errors.Wrap(tplErr, "cannot create a comment header from template")
+	}
+	marking, _ := templating.TemplateProgressComment(markingBodyPart, pipeline, markingPart)
+
+	// sections are stored atomically, but the comment itself can be created only once
+	jx.aggregatedMu.Lock()
+	defer jx.aggregatedMu.Unlock()
+
+	sections := jx.sc.Store.RecordSharedCommentSection(scmCtx.RepoHttpsUrl, scmCtx.PrId, store.CommentSection{
+		Pipeline: pipeline.GetName(),
+		Content:  strings.TrimSpace(section),
+		Commit:   scmCtx.Commit,
+		Started:  pipeline.GetDateStarted(),
+	})
+	content := strings.TrimSpace(header) + "\n\n" + joinSections(sections) + marking
+
+	if jx.sc.Store.IsSharedCommentUpToDate(scmCtx.RepoHttpsUrl, scmCtx.PrId, content) {
+		jx.sc.Log.Debugf("Skipping update, aggregated comment already up-to-date for PR '%s'", scmCtx.PrId)
+		return nil
+	}
+
+	commentId := jx.sc.Store.GetSharedCommentId(scmCtx.RepoHttpsUrl, scmCtx.PrId)
+	if commentId == "" {
+		var findErr error
+		if commentId, findErr = jx.findCommentIdByMarking(ctx, cfg, markingPart, pipeline, prId, client); findErr != nil {
+			return findErr
+		}
+	}
+
+	if commentId == "" {
+		comment, _, createErr := client.PullRequests.CreateComment(ctx, scmCtx.GetNameWithOrg(), prId, &scm.CommentInput{Body: content})
+		if createErr != nil {
+			return errors.Wrap(createErr, "cannot create a comment on a Pull Request")
+		}
+		commentId = fmt.Sprintf("%v", comment.ID)
+	} else {
+		commentIdInt, _ := strconv.Atoi(commentId)
+		_, _, editErr := client.PullRequests.EditComment(ctx, scmCtx.GetNameWithOrg(), prId, commentIdInt, &scm.CommentInput{Body: content})
+		if editErr != nil {
+			return errors.Wrap(editErr, "cannot edit existing comment on a Pull Request")
+		}
+	}
+	jx.sc.Store.RecordSharedComment(scmCtx.RepoHttpsUrl, scmCtx.PrId, commentId, content)
+	return nil
+}
+
+// joinSections puts the sections in a stable order, so the comment does not jump around on each update
+func joinSections(sections map[string]store.CommentSection) string {
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	for _, name := range names {
+		result.WriteString(sections[name].Content + "\n\n")
+	}
+	return result.String()
+}
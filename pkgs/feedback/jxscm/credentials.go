@@ -0,0 +1,60 @@
+package jxscm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+)
+
+// credentialsCacheTtl is how long resolved credentials (tokens, keys and certificates from `kind: Secret`) are reused.
+// It is shorter than the margin after which the store forgets a GitHub App installation token,
+// so a cached client never uses an expired token, and a rotated `kind: Secret` is picked up quickly
+const credentialsCacheTtl = time.Minute
+
+type resolvedClient struct {
+	client    *scm.Client
+	expiresAt time.Time
+}
+
+// getResolvedClient returns a client with credentials resolved recently for the same configuration
+func (jx *Receiver) getResolvedClient(key string) *scm.Client {
+	jx.credentialsMu.Lock()
+	defer jx.credentialsMu.Unlock()
+
+	resolved, exists := jx.resolved[key]
+	if !exists || time.Now().After(resolved.expiresAt) {
+		return nil
+	}
+	return resolved.client
+}
+
+// recordResolvedClient keeps the client for credentialsCacheTtl, and forgets the expired ones
+func (jx *Receiver) recordResolvedClient(key string, client *scm.Client) {
+	jx.credentialsMu.Lock()
+	defer jx.credentialsMu.Unlock()
+
+	now := time.Now()
+	for existingKey, resolved := range jx.resolved {
+		if now.After(resolved.expiresAt) {
+			delete(jx.resolved, existingKey)
+		}
+	}
+	jx.resolved[key] = resolvedClient{client: client, expiresAt: now.Add(credentialsCacheTtl)}
+}
+
+// createCredentialsKey identifies the credentials by the configuration referencing them. The secrets are not read,
+// as that is what the cache is avoiding. The repository owner is a part of the key, as a GitHub App may have
+// a different installation per owner
+func createCredentialsKey(data config.Data, pipeline contract.PipelineInfo) string {
+	hash := sha256.New()
+	hash.Write([]byte("namespace=" + pipeline.GetNamespace() + "\n"))
+	hash.Write([]byte("owner=" + pipeline.GetSCMContext().OrganizationName + "\n"))
+	for _, key := range append(hostScopedKeys, "timeout") {
+		hash.Write([]byte(key + "=" + data.Get(key) + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,187 @@
+package jxscm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/pkg/errors"
+)
+
+// updateDeployment reports the Pipeline as a deployment to the environment from the annotations.
+// A deployment is created once per Pipeline run, then only its status is updated when it changes
+func (jx *Receiver) updateDeployment(ctx context.Context, client *scm.Client, pipeline contract.PipelineInfo,
+	log *logging.InternalLogger) error {
+
+	var state string
+	switch client.Driver {
+	case scm.DriverGithub:
+		state = translateGitHubDeploymentStatus(pipeline.GetStatus())
+	case scm.DriverGitlab:
+		state = translateGitLabDeploymentStatus(pipeline.GetStatus())
+	default:
+		log.Debugf("Deployments are not supported for '%s'", client.Driver.String())
+		return nil
+	}
+	if state == "" || jx.sc.Store.GetDeploymentState(pipeline) == state {
+		return nil
+	}
+
+	deploymentId := jx.sc.Store.GetDeploymentId(pipeline)
+	var err error
+	if client.Driver == scm.DriverGithub {
+		deploymentId, err = updateGitHubDeployment(ctx, client, pipeline, deploymentId, state)
+	} else {
+		deploymentId, err = updateGitLabDeployment(ctx, client, pipeline, deploymentId, state)
+	}
+	if deploymentId != "" {
+		// the deployment must not be created twice, even when its status was not sent
+		recorded := state
+		if err != nil {
+			recorded = ""
+		}
+		jx.sc.Store.RecordDeploymentState(pipeline, deploymentId, recorded)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot report deployment to '%s'", pipeline.GetSCMContext().Environment)
+	}
+	log.Debugf("Deployment '%s' to '%s' is '%s'", deploymentId, pipeline.GetSCMContext().Environment, state)
+	return nil
+}
+
+// updateGitHubDeployment creates a Deployment and a Deployment Status. Returns the deployment id
+func updateGitHubDeployment(ctx context.Context, client *scm.Client, pipeline contract.PipelineInfo, deploymentId string, state string) (string, error) {
+	scmCtx := pipeline.GetSCMContext()
+	repo := scmCtx.GetNameWithOrg()
+
+	if deploymentId == "" {
+		ref := scmCtx.Commit
+		if ref == "" {
+			ref = scmCtx.Reference
+		}
+		// go-scm omits empty "required_contexts", then GitHub refuses to deploy until all commit statuses succeed,
+		// including the status of this Pipeline
+		deployment := struct {
+			Id int `json:"id"`
+		}{}
+		input := map[string]interface{}{
+			"ref":               ref,
+			"environment":       scmCtx.Environment,
+			"description":       pipeline.GetName(),
+			"auto_merge":        false,
+			"required_contexts": []string{},
+		}
+		if err := doJSON(ctx, client, http.MethodPost, fmt.Sprintf("repos/%s/deployments", repo), input, &deployment); err != nil {
+			return "", errors.Wrap(err, "cannot create a deployment")
+		}
+		deploymentId = fmt.Sprintf("%v", deployment.Id)
+	}
+
+	_, _, err := client.Deployments.CreateStatus(ctx, repo, deploymentId, &scm.DeploymentStatusInput{
+		State:           state,
+		Environment:     scmCtx.Environment,
+		EnvironmentLink: scmCtx.EnvironmentUrl,
+		LogLink:         pipeline.GetDashboardUrl(),
+		Description:     pipeline.GetStatus().AsHumanReadableDescription(),
+		AutoInactive:    true,
+	})
+	return deploymentId, errors.Wrap(err, "cannot create a deployment status")
+}
+
+// updateGitLabDeployment creates a deployment, or updates its status. The environment url is kept on the environment itself.
+// Returns the deployment id
+func updateGitLabDeployment(ctx context.Context, client *scm.Client, pipeline contract.PipelineInfo, deploymentId string, state string) (string, error) {
+	scmCtx := pipeline.GetSCMContext()
+	project := scmCtx.ProjectId
+	if project == "" {
+		project = url.PathEscape(scmCtx.GetNameWithOrg())
+	}
+
+	if deploymentId != "" {
+		err := doJSON(ctx, client, http.MethodPut, fmt.Sprintf("api/v4/projects/%s/deployments/%s", project, deploymentId),
+			map[string]string{"status": state}, &struct{}{})
+		return deploymentId, errors.Wrap(err, "cannot update deployment status")
+	}
+
+	deployment := struct {
+		Id          int `json:"id"`
+		Environment struct {
+			Id          int    `json:"id"`
+			ExternalUrl string `json:"external_url"`
+		} `json:"environment"`
+	}{}
+	input := map[string]interface{}{
+		"environment": scmCtx.Environment,
+		"sha":         scmCtx.Commit,
+		"ref":         gitLabDeploymentRef(scmCtx),
+		"tag":         scmCtx.IsTag(),
+		"status":      state,
+	}
+	if err := doJSON(ctx, client, http.MethodPost, fmt.Sprintf("api/v4/projects/%s/deployments", project), input, &deployment); err != nil {
+		return "", errors.Wrap(err, "cannot create a deployment")
+	}
+	deploymentId = fmt.Sprintf("%v", deployment.Id)
+
+	if scmCtx.EnvironmentUrl != "" && deployment.Environment.ExternalUrl != scmCtx.EnvironmentUrl {
+		err := doJSON(ctx, client, http.MethodPut, fmt.Sprintf("api/v4/projects/%s/environments/%d", project, deployment.Environment.Id),
+			map[string]string{"external_url": scmCtx.EnvironmentUrl}, &struct{}{})
+		if err != nil {
+			return deploymentId, errors.Wrap(err, "cannot set environment url")
+		}
+	}
+	return deploymentId, nil
+}
+
+// gitLabDeploymentRef returns a branch or tag name, as Gitlab does not accept full references
+func gitLabDeploymentRef(scmCtx contract.JobContext) string {
+	if scmCtx.IsTag() {
+		return scmCtx.Tag
+	}
+	if scmCtx.SourceBranch != "" {
+		return scmCtx.SourceBranch
+	}
+	if scmCtx.Reference != "" {
+		return strings.TrimPrefix(scmCtx.Reference, "refs/heads/")
+	}
+	return scmCtx.Commit
+}
+
+func translateGitHubDeploymentStatus(status contract.Status) string {
+	switch status {
+	case contract.PipelinePending:
+		return "queued"
+	case contract.PipelineRunning:
+		return "in_progress"
+	case contract.PipelineSucceeded:
+		return "success"
+	case contract.PipelineFailed:
+		return "failure"
+	case contract.PipelineErrored:
+		return "error"
+	case contract.PipelineCancelled, contract.PipelineSkipped:
+		return "inactive"
+	default:
+		return ""
+	}
+}
+
+// translateGitLabDeploymentStatus returns an empty string for pending Pipelines, as Gitlab has no such deployment status
+func translateGitLabDeploymentStatus(status contract.Status) string {
+	switch status {
+	case contract.PipelineRunning:
+		return "running"
+	case contract.PipelineSucceeded:
+		return "success"
+	case contract.PipelineFailed, contract.PipelineErrored:
+		return "failed"
+	case contract.PipelineCancelled, contract.PipelineSkipped:
+		return "canceled"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,62 @@
+package jxscm
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+)
+
+// notFoundPullRequestsCacheTtl is short, as the Pull Request is often created after the push Pipeline was started
+const notFoundPullRequestsCacheTtl = 60
+
+func isPullRequestDiscoveryEnabled(cfg config.Data) bool {
+	return cfg.GetOrDefault("discover-pr-by-commit", "false") == "true"
+}
+
+// resolvePullRequests returns a Pipeline copy for each Pull Request it should report to. When the Pipeline has no
+// Pull Request id, then optionally open Pull Requests are discovered by matching the head commit
+func (jx *Receiver) resolvePullRequests(ctx context.Context, cfg config.Data, client *scm.Client, pipeline contract.PipelineInfo,
+	log *logging.InternalLogger) []contract.PipelineInfo {
+
+	scmCtx := pipeline.GetSCMContext()
+	if scmCtx.PrId != "" {
+		return []contract.PipelineInfo{pipeline}
+	}
+	if !isPullRequestDiscoveryEnabled(cfg) || scmCtx.Commit == "" || scmCtx.RepoHttpsUrl == "" || client.PullRequests == nil {
+		return []contract.PipelineInfo{}
+	}
+
+	prIds, wasLookedUp := jx.sc.Store.GetDiscoveredPullRequests(scmCtx.RepoHttpsUrl, scmCtx.Commit)
+	if !wasLookedUp {
+		maxPages, _ := strconv.Atoi(cfg.GetOrDefault("discover-pr-max-pages", "5"))
+		found, err := jxscm.FindOpenPullRequestsByCommit(ctx, client.PullRequests, scmCtx.GetNameWithOrg(), scmCtx.Commit, maxPages)
+		if err != nil {
+			log.Warningf("Cannot discover Pull Requests for commit '%s': %s", scmCtx.Commit, err.Error())
+			return []contract.PipelineInfo{}
+		}
+		for _, prId := range found {
+			prIds = append(prIds, strconv.Itoa(prId))
+		}
+		ttl := store.StatusCacheTtl
+		if len(prIds) == 0 {
+			ttl = notFoundPullRequestsCacheTtl
+		}
+		jx.sc.Store.RecordDiscoveredPullRequests(scmCtx.RepoHttpsUrl, scmCtx.Commit, prIds, ttl)
+	}
+
+	pipelines := make([]contract.PipelineInfo, 0, len(prIds))
+	for _, prId := range prIds {
+		log.Debugf("Pipeline '%s' is associated with discovered Pull Request #%s", pipeline.GetId(), prId)
+		prCtx := scmCtx
+		prCtx.PrId = prId
+		prCtx.PrDiscovered = true
+		pipelines = append(pipelines, pipeline.WithSCMContext(prCtx))
+	}
+	return pipelines
+}
@@ -0,0 +1,130 @@
+package jxscm
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// hostScopedKeys describe the SCM server and its credentials. Those are not inherited from the top-level configuration
+// when a "jxscm.hosts" entry matches the repository, so credentials of one server are never sent to other
+var hostScopedKeys = []string{
+	"token",
+	"token-secret-name",
+	"token-secret-key",
+	"github-app-id",
+	"github-app-private-key",
+	"github-app-private-key-secret-name",
+	"github-app-private-key-secret-key",
+	"github-app-installation-id",
+	"git-repo-url",
+	"git-kind",
+	"git-server",
+	"git-token",
+	"git-user",
+	"bb-oauth-client-id",
+	"bb-oauth-client-secret",
+	"ca-bundle",
+	"ca-bundle-secret-name",
+	"ca-bundle-secret-key",
+	"ca-bundle-configmap-name",
+	"ca-bundle-configmap-key",
+	"insecure-skip-verify",
+	"client-cert",
+	"client-cert-secret-name",
+	"client-cert-secret-key",
+	"client-key",
+	"client-key-secret-name",
+	"client-key-secret-key",
+	"http-proxy",
+	"https-proxy",
+	"no-proxy",
+}
+
+// parseHosts parses "jxscm.hosts" - a YAML list of entries, each with a "host" and any "jxscm.*" keys (without the prefix)
+func parseHosts(input string) ([]map[string]string, error) {
+	entries := make([]map[string]string, 0)
+	if strings.TrimSpace(input) == "" {
+		return entries, nil
+	}
+	if err := yaml.Unmarshal([]byte(input), &entries); err != nil {
+		return nil, errors.Wrap(err, "'jxscm.hosts' is not a valid YAML list")
+	}
+	for num, entry := range entries {
+		if strings.TrimSpace(entry["host"]) == "" {
+			return nil, errors.Errorf("'jxscm.hosts' entry #%d has no 'host'", num+1)
+		}
+	}
+	return entries, nil
+}
+
+// selectHostConfig applies the "jxscm.hosts" entry matching the repository host on top of the configuration.
+// Missing "git-kind" is taken from the top-level configuration when its "git-server" is the same host, else it is guessed
+// from the host name. Missing "git-server" is the repository host.
+// When no entry matches, then the configuration is returned as it is
+func selectHostConfig(cfg config.Data, repoHttpsUrl string) (config.Data, error) {
+	entries, err := parseHosts(cfg.Get("hosts"))
+	if err != nil || len(entries) == 0 {
+		return cfg, err
+	}
+	repoUrl, urlErr := url.Parse(repoHttpsUrl)
+	if urlErr != nil || repoUrl.Host == "" {
+		return cfg, nil
+	}
+
+	for _, entry := range entries {
+		if !matchesHost(entry["host"], repoUrl) {
+			continue
+		}
+		values := make(map[string]string, len(entry)+1)
+		for key, value := range entry {
+			if key != "host" && key != "hosts" {
+				values[key] = value
+			}
+		}
+		if values["git-kind"] == "" && cfg.Get("git-kind") != "" && isServerOfRepository(cfg.Get("git-server"), repoUrl) {
+			values["git-kind"] = cfg.Get("git-kind")
+		}
+		if values["git-kind"] == "" {
+			values["git-kind"] = jxscm.GuessDriverFromHost(repoUrl.Hostname())
+			if values["git-kind"] == "" {
+				return cfg, errors.Errorf("cannot guess 'git-kind' of '%s', please set it in 'jxscm.hosts'", entry["host"])
+			}
+		}
+		if values["git-server"] == "" && values["git-repo-url"] == "" {
+			values["git-server"] = repoUrl.Scheme + "://" + repoUrl.Host
+		}
+		hostCfg, overrideErr := cfg.WithOverrides(values, hostScopedKeys)
+		if overrideErr != nil {
+			return cfg, errors.Wrapf(overrideErr, "invalid 'jxscm.hosts' entry for '%s'", entry["host"])
+		}
+		return hostCfg, nil
+	}
+	return cfg, nil
+}
+
+// isServerOfRepository checks that the "git-server" url points to the repository host
+func isServerOfRepository(server string, repoUrl *url.URL) bool {
+	server = strings.TrimSpace(server)
+	if server == "" {
+		return false
+	}
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	serverUrl, err := url.Parse(server)
+	if err != nil || serverUrl.Host == "" {
+		return false
+	}
+	return matchesHost(serverUrl.Host, repoUrl)
+}
+
+// matchesHost compares the host with, or without the port
+func matchesHost(host string, repoUrl *url.URL) bool {
+	host = strings.ToLower(strings.TrimSpace(host))
+	return host == strings.ToLower(repoUrl.Host) || host == strings.ToLower(repoUrl.Hostname())
+}
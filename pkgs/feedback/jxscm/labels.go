@@ -0,0 +1,101 @@
+package jxscm
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/pkg/errors"
+)
+
+// labelsMaxPages limits listing of Pull Request labels, a Pull Request rarely has more than a single page of labels
+const labelsMaxPages = 10
+
+// updatePRLabels keeps a single status label on the Pull Request, reflecting statuses of all Pipelines of the Pull Request:
+// failed when any Pipeline failed, running when any is still running, passed when all passed. Only Pipelines
+// of the latest commit are considered
+func (jx *Receiver) updatePRLabels(ctx context.Context, cfg config.Data, client *scm.Client, pipeline contract.PipelineInfo,
+	log *logging.InternalLogger) error {
+
+	// other SCMs emulate labels with comments
+	if client.Driver != scm.DriverGithub && client.Driver != scm.DriverGitlab && client.Driver != scm.DriverGitea {
+		log.Debugf("Pull Request labels are not supported for '%s'", client.Driver.String())
+		return nil
+	}
+	scmCtx := pipeline.GetSCMContext()
+	managed := map[string]string{
+		"running": cfg.GetOrDefault("pr-label-running", "ci/running"),
+		"failed":  cfg.GetOrDefault("pr-label-failed", "ci/failed"),
+		"passed":  cfg.GetOrDefault("pr-label-passed", "ci/passed"),
+	}
+
+	jx.aggregatedMu.Lock()
+	defer jx.aggregatedMu.Unlock()
+
+	statuses := jx.sc.Store.RecordPullRequestPipelineStatus(scmCtx.RepoHttpsUrl, scmCtx.PrId, pipeline.GetName(), scmCtx.Commit,
+		pipeline.GetDateStarted(), pipeline.GetStatus())
+	desired := managed[aggregateStatus(statuses)]
+	if jx.sc.Store.GetPullRequestLabel(scmCtx.RepoHttpsUrl, scmCtx.PrId) == desired {
+		return nil
+	}
+
+	prId, _ := strconv.Atoi(scmCtx.PrId)
+	repo := scmCtx.GetNameWithOrg()
+	current, listErr := jxscm.ListPullRequestLabels(ctx, client.PullRequests, repo, prId, labelsMaxPages)
+	if listErr != nil {
+		return listErr
+	}
+	hasDesired := false
+	for _, label := range current {
+		if label.Name == desired {
+			hasDesired = true
+			continue
+		}
+		for _, name := range managed {
+			if label.Name != name {
+				continue
+			}
+			if _, err := client.PullRequests.DeleteLabel(ctx, repo, prId, escapeLabelInPath(client, label.Name)); err != nil {
+				return errors.Wrapf(err, "cannot remove label '%s' from a Pull Request", label.Name)
+			}
+		}
+	}
+	if !hasDesired {
+		if _, err := client.PullRequests.AddLabel(ctx, repo, prId, desired); err != nil {
+			return errors.Wrapf(err, "cannot add label '%s' to a Pull Request", desired)
+		}
+	}
+	jx.sc.Store.RecordPullRequestLabel(scmCtx.RepoHttpsUrl, scmCtx.PrId, desired)
+	return nil
+}
+
+// escapeLabelInPath escapes e.g. "/" in "ci/running", as the GitHub driver puts the label name in the URL path as it is
+func escapeLabelInPath(client *scm.Client, label string) string {
+	if client.Driver == scm.DriverGithub {
+		return url.PathEscape(label)
+	}
+	return label
+}
+
+// aggregateStatus returns "failed", "running" or "passed" for statuses of multiple Pipelines. Cancelled Pipeline is not
+// considered as passed, skipped is
+func aggregateStatus(statuses map[string]contract.Status) string {
+	running := false
+	for _, status := range statuses {
+		if status.IsErroredOrFailed() || status.IsCancelled() {
+			return "failed"
+		}
+		if status.IsRunning() || status == contract.PipelinePending {
+			running = true
+		}
+	}
+	if running {
+		return "running"
+	}
+	return "passed"
+}
@@ -0,0 +1,82 @@
+package jxscm
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+)
+
+// fillGitMetadata is lazily completing the GIT metadata (branches, author, commit title, project id) that was not
+// provided in the annotations. Metadata is fetched from the SCM API once per commit and Pull Request, then kept in the store.
+// Opt-in, as it costs a few additional SCM API calls per commit
+func (jx *Receiver) fillGitMetadata(ctx context.Context, cfg config.Data, client *scm.Client, pipeline contract.PipelineInfo,
+	log *logging.InternalLogger) contract.PipelineInfo {
+
+	if cfg.GetOrDefault("fetch-git-metadata", "false") != "true" {
+		return pipeline
+	}
+	scmCtx := pipeline.GetSCMContext()
+	cacheKey := scmCtx.RepoHttpsUrl + "/" + scmCtx.Commit + "/" + scmCtx.PrId
+	if cached, exists := jx.sc.Store.GetCachedJobContext(cacheKey); exists {
+		return pipeline.WithSCMContext(scmCtx.InheritFrom(withRepository(cached, scmCtx)))
+	}
+
+	fetched := withRepository(contract.JobContext{}, scmCtx)
+	succeeded := true
+	repo := scmCtx.GetNameWithOrg()
+
+	// commit author and message
+	if scmCtx.Commit != "" && (scmCtx.CommitAuthor == "" || scmCtx.CommitTitle == "") && client.Git != nil {
+		if commit, _, err := client.Git.FindCommit(ctx, repo, scmCtx.Commit); err == nil && commit != nil {
+			fetched.CommitAuthor = commit.Author.Name
+			if fetched.CommitAuthor == "" {
+				fetched.CommitAuthor = commit.Author.Login
+			}
+			fetched.CommitTitle = strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0])
+		} else if err != nil {
+			succeeded = false
+			log.Debugf("Cannot fetch commit '%s' details from SCM: %s", scmCtx.Commit, err.Error())
+		}
+	}
+
+	// PR source and target branches
+	if scmCtx.PrId != "" && (scmCtx.SourceBranch == "" || scmCtx.TargetBranch == "") && client.PullRequests != nil {
+		prId, _ := strconv.Atoi(scmCtx.PrId)
+		if pr, _, err := client.PullRequests.Find(ctx, repo, prId); err == nil && pr != nil {
+			fetched.SourceBranch = pr.Source
+			fetched.TargetBranch = pr.Target
+		} else if err != nil {
+			succeeded = false
+			log.Debugf("Cannot fetch Pull Request '%s' details from SCM: %s", scmCtx.PrId, err.Error())
+		}
+	}
+
+	// numeric project ID
+	if scmCtx.ProjectId == "" && client.Driver == scm.DriverGitlab && client.Repositories != nil {
+		if repository, _, err := client.Repositories.Find(ctx, repo); err == nil && repository != nil {
+			fetched.ProjectId = repository.ID
+		} else if err != nil {
+			succeeded = false
+			log.Debugf("Cannot fetch project '%s' details from SCM: %s", repo, err.Error())
+		}
+	}
+
+	// do not cache partial results, let the next reconciliation retry
+	if succeeded {
+		jx.sc.Store.RecordJobContext(cacheKey, fetched)
+	}
+	return pipeline.WithSCMContext(scmCtx.InheritFrom(fetched))
+}
+
+// withRepository points the fetched metadata to the repository of the Pipeline, so it can be inherited
+func withRepository(fetched contract.JobContext, scmCtx contract.JobContext) contract.JobContext {
+	fetched.RepoHttpsUrl = scmCtx.RepoHttpsUrl
+	fetched.OrganizationName = scmCtx.OrganizationName
+	fetched.RepositoryName = scmCtx.RepositoryName
+	return fetched
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/githubapp"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
 	"github.com/pkg/errors"
@@ -43,6 +45,14 @@ The Pipeline finished with status '{{ .pipeline.GetStatus }}' {{ if .pipeline.Ge
 {{ if .pipeline.GetDashboardUrl }}- [Open in dashboard]({{ .pipeline.GetDashboardUrl }}){{ end }}
 `
 
+// defaultStatusContext is stable across reruns, so the SCM can require it in the branch protection
+const defaultStatusContext = `{{ .pipeline.GetName }}`
+const defaultStatusDescription = `{{ .pipeline.GetStatus.AsHumanReadableDescription }}`
+const defaultStageStatusDescription = `{{ .stage.Status.AsHumanReadableDescription }}`
+
+// maxStatusDescriptionLength is a GitHub limit, longer descriptions are rejected
+const maxStatusDescriptionLength = 140
+
 const markingBodyPart = `
 
 <details>
@@ -52,38 +62,61 @@ const markingBodyPart = `
 `
 
 type Receiver struct {
-	sc *wiring.ServiceContext
+	sc   *wiring.ServiceContext
+	pool *jxscm.ClientPool
+
+	// aggregatedMu serializes updates of the PR comments and labels shared by multiple Pipelines
+	aggregatedMu sync.Mutex
+
+	credentialsMu sync.Mutex
+	resolved      map[string]resolvedClient
 }
 
 // updatePRStatusComment is keeping the PR comment up-to-date with the detailed status of the Pipeline. The comment
 //
 //	will be created, and then edited multiple times
-func (jx *Receiver) updatePRStatusComment(ctx context.Context, cfg config.Data, pipeline contract.PipelineInfo) error {
+func (jx *Receiver) updatePRStatusComment(ctx context.Context, cfg config.Data, client *scm.Client, pipeline contract.PipelineInfo) error {
 	// if we are not in context of a PR, then it makes no sense to proceed
 	if pipeline.GetSCMContext().PrId == "" {
 		return nil
 	}
 
-	client, clientErr := jx.createClient(ctx, cfg, pipeline)
-	if clientErr != nil {
-		return errors.Wrap(clientErr, "cannot create/update PR status comment, SCM client error")
+	if isAggregatedCommentMode(cfg) {
+		return jx.updateAggregatedPRComment(ctx, cfg, client, pipeline)
 	}
 
 	prId, _ := strconv.Atoi(pipeline.GetSCMContext().PrId)
 	markingPart := "(pfc-id=" + pipeline.GetId() + "/updatePRStatusComment)" // we identify a comment by this marking
 
-	// 1. Find existing comment in the cache
+	// 1. Check cache - skip if last status is the same as current (then we do not need to edit anything)
+	if jx.sc.Store.IsPRCommentUpToDate(pipeline) {
+		jx.sc.Log.Debugf("Skipping update, status already wrote to SCM for '%s'", pipeline.GetId())
+		return nil
+	}
+	// a newer run has already collapsed, minimized or deleted the comment
+	if jx.sc.Store.IsPipelineRunSuperseded(pipeline) {
+		jx.sc.Log.Debugf("Skipping update, comment of '%s' was superseded by a newer run", pipeline.GetId())
+		return nil
+	}
+
+	// 2. Find existing comment in the cache
 	commentId := jx.sc.Store.GetStatusPRCommentId(pipeline)
 
-	// 2. If not, then search through the comments in the PR
+	// 2.1. If not, then search through the comments in the PR. The marking part without its prefix is found also
+	//      in a collapsed comment
 	if commentId == "" {
-		commentId = jx.findCommentIdByMarking(ctx, markingPart, pipeline, prId, client)
-	}
-
-	// 2.1. Check cache - skip if last status is the same as current (then we do not need to edit anything)
-	if jx.sc.Store.GetLastRecordedPipelineStatus(pipeline) == string(pipeline.GetStatus()) {
-		jx.sc.Log.Debugf("Skipping update, status already wrote to SCM for '%s'", pipeline.GetId())
-		return nil
+		comment, findErr := jx.findCommentByMarking(ctx, cfg, "="+pipeline.GetId()+"/updatePRStatusComment)", pipeline, prId, client)
+		if findErr != nil {
+			return findErr
+		}
+		if comment != nil && strings.Contains(comment.Body, supersededMarkingPart) {
+			jx.sc.Log.Debugf("Skipping update, comment '%d' of '%s' was superseded by a newer run", comment.ID, pipeline.GetId())
+			jx.sc.Store.RecordPipelineRunSuperseded(pipeline.GetId(), pipeline.GetSCMContext().PrId)
+			return nil
+		}
+		if comment != nil {
+			commentId = fmt.Sprintf("%v", comment.ID)
+		}
 	}
 
 	content, tplErr := templating.TemplateProgressComment(
@@ -97,13 +130,13 @@ func (jx *Receiver) updatePRStatusComment(ctx context.Context, cfg config.Data,
 
 	// 3. Create new comment
 	if commentId == "" {
-		_, _, createErr := client.PullRequests.CreateComment(ctx, pipeline.GetSCMContext().GetNameWithOrg(), prId, &scm.CommentInput{
+		comment, _, createErr := client.PullRequests.CreateComment(ctx, pipeline.GetSCMContext().GetNameWithOrg(), prId, &scm.CommentInput{
 			Body: content,
 		})
 		if createErr != nil {
 			return errors.Wrap(createErr, "cannot create a comment on a Pull Request")
 		}
-		jx.sc.Store.RecordInfoAboutLastComment(pipeline, commentId)
+		jx.sc.Store.RecordInfoAboutLastComment(pipeline, fmt.Sprintf("%v", comment.ID))
 	} else {
 		// 4. Update existing comment
 		commentIdInt, _ := strconv.Atoi(commentId)
@@ -121,6 +154,8 @@ func (jx *Receiver) updatePRStatusComment(ctx context.Context, cfg config.Data,
 func (jx *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
 	sc.Log.Info("Initializing JX SCM Receiver")
 	jx.sc = sc
+	jx.pool = jxscm.NewClientPool(jxscm.DefaultMaxIdleTime)
+	jx.resolved = make(map[string]resolvedClient)
 
 	// register configuration options
 	jx.sc.ConfigSchema.Add(config.Schema{
@@ -129,6 +164,11 @@ func (jx *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
 			"token",
 			"token-secret-name",
 			"token-secret-key",
+			"github-app-id",
+			"github-app-private-key",
+			"github-app-private-key-secret-name",
+			"github-app-private-key-secret-key",
+			"github-app-installation-id",
 			"git-repo-url",
 			"git-kind",
 			"git-server",
@@ -136,8 +176,44 @@ func (jx *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
 			"git-user",
 			"bb-oauth-client-id",
 			"bb-oauth-client-secret",
+			"hosts",
+			"timeout",
+			"ca-bundle",
+			"ca-bundle-secret-name",
+			"ca-bundle-secret-key",
+			"ca-bundle-configmap-name",
+			"ca-bundle-configmap-key",
+			"insecure-skip-verify",
+			"client-cert",
+			"client-cert-secret-name",
+			"client-cert-secret-key",
+			"client-key",
+			"client-key-secret-name",
+			"client-key-secret-key",
+			"http-proxy",
+			"https-proxy",
+			"no-proxy",
+			"status-context",
+			"status-description",
+			"stage-statuses",
+			"stage-status-prefix",
+			"stage-status-context",
+			"stage-status-description",
 			"progress-comment",
+			"progress-comment-mode",
+			"aggregated-comment-header",
+			"aggregated-comment-section",
 			"finished-comment",
+			"superseded-comments",
+			"comment-search-max-pages",
+			"pr-labels",
+			"pr-label-running",
+			"pr-label-failed",
+			"pr-label-passed",
+			"deployments",
+			"fetch-git-metadata",
+			"discover-pr-by-commit",
+			"discover-pr-max-pages",
 		},
 	})
 	return nil
@@ -158,28 +234,49 @@ func (jx *Receiver) WhenFinished(ctx context.Context, pipeline contract.Pipeline
 	}
 
 	// Skip if not in a PR context
-	if pipeline.GetSCMContext().PrId == "" {
+	cfg, cfgErr := jx.fetchConfig(pipeline)
+	if cfgErr != nil {
+		return cfgErr
+	}
+	if pipeline.GetSCMContext().PrId == "" && !isPullRequestDiscoveryEnabled(cfg) {
 		return nil
 	}
 
-	cfg := jx.sc.Config.FetchContextual("jxscm", pipeline.GetNamespace(), pipeline)
 	client, clientErr := jx.createClient(ctx, cfg, pipeline)
 	if clientErr != nil {
 		return errors.Wrap(clientErr, "cannot create/update PR status comment, SCM client error")
 	}
 
+	pipeline = jx.fillGitMetadata(ctx, cfg, client, pipeline, log)
+	for _, prPipeline := range jx.resolvePullRequests(ctx, cfg, client, pipeline, log) {
+		if err := jx.createSummaryComment(ctx, cfg, client, prPipeline, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createSummaryComment is creating a final comment once per Pipeline and Pull Request
+func (jx *Receiver) createSummaryComment(ctx context.Context, cfg config.Data, client *scm.Client, pipeline contract.PipelineInfo,
+	log *logging.InternalLogger) error {
+
 	prId, _ := strconv.Atoi(pipeline.GetSCMContext().PrId)
 	markingPart := "(pfc-id=" + pipeline.GetId() + "/WhenFinished)"
 
-	// Do not send the same comment twice
-	if jx.sc.Store.WasSummaryCommentCreated(pipeline) {
+	// Do not send the same comment twice, neither comment a run superseded by a newer one
+	if jx.sc.Store.WasSummaryCommentCreated(pipeline) || jx.sc.Store.IsPipelineRunSuperseded(pipeline) {
 		log.Debugf("Skipping update, status already written to SCM for '%s'", pipeline.GetId())
 		return nil
 
 	} else {
 		// Fallback - in case there was no cache
-		if jx.findCommentIdByMarking(ctx, markingPart, pipeline, prId, client) != "" {
+		commentId, findErr := jx.findCommentIdByMarking(ctx, cfg, markingPart, pipeline, prId, client)
+		if findErr != nil {
+			return findErr
+		}
+		if commentId != "" {
 			log.Debugf("Skipping update, status already written to SCM for '%s'", pipeline.GetId())
+			jx.sc.Store.RecordSummaryCommentCreated(pipeline)
 			return nil
 		}
 	}
@@ -211,21 +308,36 @@ func (jx *Receiver) UpdateProgress(ctx context.Context, pipeline contract.Pipeli
 		return nil
 	}
 
-	cfg := jx.sc.Config.FetchContextual("jxscm", pipeline.GetNamespace(), pipeline)
+	cfg, cfgErr := jx.fetchConfig(pipeline)
+	if cfgErr != nil {
+		return cfgErr
+	}
 	client, clientErr := jx.createClient(ctx, cfg, pipeline)
 	if clientErr != nil {
 		return errors.Wrap(clientErr, "cannot create/update PR status comment, SCM client error")
 	}
 
+	pipeline = jx.fillGitMetadata(ctx, cfg, client, pipeline, log)
 	scmCtx := pipeline.GetSCMContext()
 	ourStatus := pipeline.GetStatus()
 	overallStatus := jx.translateStatus(ourStatus)
 
 	// Update status in PR/MR comment
 	var prCommentStatusErr error = nil
-	if commentStatusErr := jx.updatePRStatusComment(ctx, cfg, pipeline); commentStatusErr != nil {
-		prCommentStatusErr = errors.Wrap(commentStatusErr, "cannot create/update status comment in PR")
-		log.Warningf("updatePRStatusComment(): %v", prCommentStatusErr.Error())
+	for _, prPipeline := range jx.resolvePullRequests(ctx, cfg, client, pipeline, log) {
+		if supersededErr := jx.handleSupersededComments(ctx, cfg, client, prPipeline, log); supersededErr != nil {
+			log.Warningf("handleSupersededComments(): %v", supersededErr.Error())
+		}
+		if commentStatusErr := jx.updatePRStatusComment(ctx, cfg, client, prPipeline); commentStatusErr != nil {
+			prCommentStatusErr = errors.Wrap(commentStatusErr, "cannot create/update status comment in PR")
+			log.Warningf("updatePRStatusComment(): %v", prCommentStatusErr.Error())
+		}
+		// labels are not critical, a failure is not retried
+		if cfg.GetOrDefault("pr-labels", "false") == "true" {
+			if labelsErr := jx.updatePRLabels(ctx, cfg, client, prPipeline, log); labelsErr != nil {
+				log.Warningf("updatePRLabels(): cannot update labels of PR: %v", labelsErr.Error())
+			}
+		}
 	}
 
 	// Update Commit status
@@ -234,6 +346,21 @@ func (jx *Receiver) UpdateProgress(ctx context.Context, pipeline contract.Pipeli
 	if commitStatusErr != nil {
 		return errors.Wrap(commitStatusErr, "cannot update commit status")
 	}
+	if cfg.GetOrDefault("stage-statuses", "false") == "true" {
+		if stageStatusErr := jx.updateStageStatuses(ctx, cfg, client, pipeline, log); stageStatusErr != nil {
+			return stageStatusErr
+		}
+	}
+	// a failed intermediate deployment status is not retried, as the next update sends a newer one anyway.
+	// The final status is retried, otherwise the deployment would be left e.g. "in_progress" forever
+	if scmCtx.IsDeployment() && cfg.GetOrDefault("deployments", "false") == "true" {
+		if deploymentErr := jx.updateDeployment(ctx, client, pipeline, log); deploymentErr != nil {
+			if ourStatus.IsFinished() {
+				return deploymentErr
+			}
+			log.Warningf("updateDeployment(): %v", deploymentErr.Error())
+		}
+	}
 	if prCommentStatusErr != nil {
 		return errors.Wrap(prCommentStatusErr, "cannot update PR comment")
 	}
@@ -243,41 +370,102 @@ func (jx *Receiver) UpdateProgress(ctx context.Context, pipeline contract.Pipeli
 func (jx *Receiver) updateCommitStatus(ctx context.Context, cfg config.Data, client *scm.Client, overallStatus scm.State, ourStatus contract.Status,
 	scmCtx contract.JobContext, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
 
-	var commitStatusErr error = nil
-	if client.Repositories != nil {
-		var response *scm.Response
-		_, response, commitStatusErr = client.Repositories.CreateStatus(ctx, pipeline.GetSCMContext().GetNameWithOrg(),
-			scmCtx.Commit, &scm.StatusInput{
-				State:  overallStatus,
-				Label:  "Pipeline - " + pipeline.GetFullName(),
-				Desc:   ourStatus.AsHumanReadableDescription(),
-				Target: pipeline.GetDashboardUrl(),
-			},
-		)
-
-		if commitStatusErr != nil {
-			// <Gitlab fix>
-			// https://github.com/kube-cicd/pipelines-feedback-core/issues/8
-			if response.Status == 400 && cfg.Get("git-kind") == "gitlab" {
-				if ourStatus == contract.PipelinePending || ourStatus == contract.PipelineRunning {
-					log.Debug("Mitigating Gitlab behavior. Cannot send a Pending/Running status twice")
-					commitStatusErr = nil
-				}
-			}
-			// <End of Gitlab fix>
+	if client.Repositories == nil {
+		log.Warning("jx.client.Repositories is nil. No support for commit status update for this SCM provider in jx go-scm?")
+		return nil
+	}
+	label, desc, tplErr := templateStatus(cfg.GetOrDefault("status-context", defaultStatusContext),
+		cfg.GetOrDefault("status-description", defaultStatusDescription), pipeline, nil)
+	if tplErr != nil {
+		return tplErr
+	}
+	return jx.createStatus(ctx, cfg, client, ourStatus, pipeline, &scm.StatusInput{
+		State:  overallStatus,
+		Label:  label,
+		Desc:   desc,
+		Target: pipeline.GetDashboardUrl(),
+	}, log)
+}
 
-			var responseTxt []byte
-			_, _ = response.Body.Read(responseTxt)
-			log.Debugf("SCM gave response: status=%v, body=%v", response.Status, responseTxt)
+// updateStageStatuses is sending a separate commit status per stage, so the branch protection can require specific stages.
+// Only stages which status changed since last time are sent
+func (jx *Receiver) updateStageStatuses(ctx context.Context, cfg config.Data, client *scm.Client, pipeline contract.PipelineInfo,
+	log *logging.InternalLogger) error {
 
-			for name, value := range response.Header {
-				log.Debugf("SCM header: %v = %v", name, value)
-			}
+	if client.Repositories == nil {
+		return nil
+	}
+	// "stage-status-prefix" is a shortcut for the most common "stage-status-context"
+	contextTpl := cfg.GetOrDefault("stage-status-context", cfg.GetOrDefault("stage-status-prefix", "ci/")+"{{ .stage.Name }}")
+	descriptionTpl := cfg.GetOrDefault("stage-status-description", defaultStageStatusDescription)
+
+	for _, stage := range pipeline.GetStages() {
+		if jx.sc.Store.GetLastRecordedStageStatus(pipeline, stage.Name) == string(stage.Status) {
+			continue
 		}
-	} else {
-		log.Warning("jx.client.Repositories is nil. No support for commit status update for this SCM provider in jx go-scm?")
+		label, desc, tplErr := templateStatus(contextTpl, descriptionTpl, pipeline, &stage)
+		if tplErr != nil {
+			return tplErr
+		}
+		err := jx.createStatus(ctx, cfg, client, stage.Status, pipeline, &scm.StatusInput{
+			State:  jx.translateStatus(stage.Status),
+			Label:  label,
+			Desc:   desc,
+			Target: pipeline.GetDashboardUrl(),
+		}, log)
+		if err != nil {
+			return errors.Wrapf(err, "cannot update commit status of stage '%s'", stage.Name)
+		}
+		jx.sc.Store.RecordStageStatus(pipeline, stage.Name, stage.Status)
+	}
+	return nil
+}
+
+// templateStatus renders the commit status context (label) and description
+func templateStatus(contextTpl string, descriptionTpl string, pipeline contract.PipelineInfo, stage *contract.PipelineStage) (string, string, error) {
+	label, err := templating.TemplateCommitStatus(contextTpl, "context", pipeline, stage)
+	if err != nil {
+		return "", "", err
 	}
+	if label == "" {
+		return "", "", errors.New("commit status context cannot be empty, please check 'jxscm.status-context' and 'jxscm.stage-status-context'")
+	}
+	desc, err := templating.TemplateCommitStatus(descriptionTpl, "description", pipeline, stage)
+	if err != nil {
+		return "", "", err
+	}
+	if runes := []rune(desc); len(runes) > maxStatusDescriptionLength {
+		desc = string(runes[:maxStatusDescriptionLength-3]) + "..."
+	}
+	return label, desc, nil
+}
+
+func (jx *Receiver) createStatus(ctx context.Context, cfg config.Data, client *scm.Client, ourStatus contract.Status,
+	pipeline contract.PipelineInfo, input *scm.StatusInput, log *logging.InternalLogger) error {
+
+	_, response, commitStatusErr := client.Repositories.CreateStatus(ctx, pipeline.GetSCMContext().GetNameWithOrg(),
+		pipeline.GetSCMContext().Commit, input)
 
+	// no response at all e.g. on TLS or proxy errors
+	if commitStatusErr != nil && response != nil {
+		// <Gitlab fix>
+		// https://github.com/kube-cicd/pipelines-feedback-core/issues/8
+		if response.Status == 400 && cfg.Get("git-kind") == "gitlab" {
+			if ourStatus == contract.PipelinePending || ourStatus == contract.PipelineRunning {
+				log.Debug("Mitigating Gitlab behavior. Cannot send a Pending/Running status twice")
+				commitStatusErr = nil
+			}
+		}
+		// <End of Gitlab fix>
+
+		var responseTxt []byte
+		_, _ = response.Body.Read(responseTxt)
+		log.Debugf("SCM gave response: status=%v, body=%v", response.Status, responseTxt)
+
+		for name, value := range response.Header {
+			log.Debugf("SCM header: %v = %v", name, value)
+		}
+	}
 	return commitStatusErr
 }
 
@@ -312,18 +500,70 @@ func createTemplate(bodyTemplate string) string {
 	return bodyTemplate + markingBodyPart
 }
 
-// findCommentIdByMarking finds an SCM comment id in a pull request by looking for a text (markingPart) in a comment body. Returns first occurrence
-func (jx *Receiver) findCommentIdByMarking(ctx context.Context, markingPart string, pipeline contract.PipelineInfo, prId int, client *scm.Client) string {
-	comments, _, _ := client.PullRequests.ListComments(ctx, pipeline.GetSCMContext().GetNameWithOrg(), prId, &scm.ListOptions{Size: 100})
-	for _, comment := range comments {
-		if strings.Contains(comment.Body, markingPart) {
-			return fmt.Sprintf("%v", comment.ID)
-		}
+// findCommentIdByMarking finds an SCM comment id in a pull request by looking for a text (markingPart) in a comment body.
+// Returns the newest occurrence, or an empty string when not found
+func (jx *Receiver) findCommentIdByMarking(ctx context.Context, cfg config.Data, markingPart string, pipeline contract.PipelineInfo,
+	prId int, client *scm.Client) (string, error) {
+
+	comment, err := jx.findCommentByMarking(ctx, cfg, markingPart, pipeline, prId, client)
+	if err != nil || comment == nil {
+		return "", err
 	}
-	return ""
+	return fmt.Sprintf("%v", comment.ID), nil
 }
 
+// findCommentByMarking returns the newest comment containing the markingPart, nil when not found
+func (jx *Receiver) findCommentByMarking(ctx context.Context, cfg config.Data, markingPart string, pipeline contract.PipelineInfo,
+	prId int, client *scm.Client) (*scm.Comment, error) {
+
+	maxPages, _ := strconv.Atoi(cfg.GetOrDefault("comment-search-max-pages", "20"))
+	comment, err := jxscm.FindPullRequestComment(ctx, client.PullRequests, client.Driver, pipeline.GetSCMContext().GetNameWithOrg(), prId, markingPart, maxPages)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot search for an existing comment in a Pull Request")
+	}
+	return comment, nil
+}
+
+// fetchConfig returns the configuration for the Pipeline, with the "jxscm.hosts" entry matching the repository applied
+func (jx *Receiver) fetchConfig(pipeline contract.PipelineInfo) (config.Data, error) {
+	cfg := jx.sc.Config.FetchContextual("jxscm", pipeline.GetNamespace(), pipeline)
+	hostCfg, err := selectHostConfig(cfg, pipeline.GetSCMContext().RepoHttpsUrl)
+	if err != nil {
+		return cfg, errors.Wrap(err, "cannot select SCM server configuration by the repository host")
+	}
+	return hostCfg, nil
+}
+
+// createClient returns a client with resolved credentials. The credentials are resolved again after credentialsCacheTtl
 func (jx *Receiver) createClient(ctx context.Context, data config.Data, pipeline contract.PipelineInfo) (*scm.Client, error) {
+	key := createCredentialsKey(data, pipeline)
+	if client := jx.getResolvedClient(key); client != nil {
+		return client, nil
+	}
+	client, err := jx.resolveClient(ctx, data, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	jx.recordResolvedClient(key, client)
+	return client, nil
+}
+
+// resolveClient fetches the credentials, TLS and proxy settings, then takes a client from the pool
+func (jx *Receiver) resolveClient(ctx context.Context, data config.Data, pipeline contract.PipelineInfo) (*scm.Client, error) {
+	transportOpts, transportErr := jx.fetchTransportOptions(ctx, data, pipeline)
+	if transportErr != nil {
+		return nil, errors.Wrap(transportErr, "cannot create a JX SCM client - cannot configure TLS or proxy")
+	}
+
+	// GitHub App has precedence over a static token
+	if data.Get("github-app-id") != "" {
+		appToken, err := jx.createGitHubAppToken(ctx, data, pipeline, transportOpts)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot create a JX SCM client - cannot authenticate as GitHub App")
+		}
+		return jx.pool.Get(data, appToken, transportOpts)
+	}
+
 	// will first try to fetch GIT token from "jxscm.token" (plaintext in configuration)
 	// fallbacks to looking for a `kind: Secret` specified by name in "jxscm.token-secret-name", and there it will look for a key specified by "jxscm.token-secret-key"
 	gitToken, err := jx.sc.Config.FetchFromFieldOrSecret(ctx, &data, pipeline.GetNamespace(), "token", "token-secret-key", "token-secret-name")
@@ -334,6 +574,39 @@ func (jx *Receiver) createClient(ctx context.Context, data config.Data, pipeline
 		return nil, errors.New("cannot create a JX SCM client - cannot fetch a GIT token neither from 'jxscm.token' as plaintext neither from a `kind: Secret` referenced in 'jxscm.token-secret-name'")
 	}
 
-	// constructs a client
-	return jxscm.NewClientFromConfig(data, gitToken)
+	// reuses a client, as long as the configuration, the token and the TLS/proxy settings did not change
+	return jx.pool.Get(data, gitToken, transportOpts)
+}
+
+// createGitHubAppToken exchanges the GitHub App credentials for an installation token. The installation is found
+// by the repository owner, unless "jxscm.github-app-installation-id" is specified
+func (jx *Receiver) createGitHubAppToken(ctx context.Context, data config.Data, pipeline contract.PipelineInfo,
+	transportOpts jxscm.TransportOptions) (string, error) {
+
+	if kind := data.GetOrDefault("git-kind", "github"); kind != "github" {
+		return "", errors.Errorf("GitHub App authentication is not supported for git-kind '%s'", kind)
+	}
+	// "jxscm.github-app-private-key" as plaintext, or a `kind: Secret` referenced by "jxscm.github-app-private-key-secret-name" and "jxscm.github-app-private-key-secret-key"
+	privateKey, err := jx.sc.Config.FetchFromFieldOrSecret(ctx, &data, pipeline.GetNamespace(), "github-app-private-key",
+		"github-app-private-key-secret-key", "github-app-private-key-secret-name")
+	if err != nil {
+		return "", errors.Wrap(err, "cannot fetch GitHub App private key neither from 'jxscm.github-app-private-key' as plaintext neither from a `kind: Secret` referenced in 'jxscm.github-app-private-key-secret-name'")
+	}
+	if privateKey == "" {
+		return "", errors.New("cannot fetch GitHub App private key neither from 'jxscm.github-app-private-key' as plaintext neither from a `kind: Secret` referenced in 'jxscm.github-app-private-key-secret-name'")
+	}
+	app, err := githubapp.NewApp(data.Get("github-app-id"), privateKey, jxscm.GitHubApiUrl(data), jx.sc.Store)
+	if err != nil {
+		return "", err
+	}
+	transport, err := jxscm.NewTransport(transportOpts)
+	if err != nil {
+		return "", err
+	}
+	app.Client.Transport = transport
+	if installationId := data.Get("github-app-installation-id"); installationId != "" {
+		return app.GetInstallationToken(ctx, installationId)
+	}
+	scmCtx := pipeline.GetSCMContext()
+	return app.GetRepositoryToken(ctx, scmCtx.OrganizationName, scmCtx.RepositoryName)
 }
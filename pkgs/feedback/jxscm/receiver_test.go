@@ -0,0 +1,859 @@
+package jxscm_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/jxscm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type apiCall struct {
+	method        string
+	path          string
+	authorization string
+	body          map[string]interface{}
+}
+
+// fakeGitHub is a local HTTP server pretending to be a GitHub Enterprise API
+type fakeGitHub struct {
+	sync.Mutex
+	server    *httptest.Server
+	calls     []apiCall
+	comments  []fakeComment
+	minimized []string
+
+	failListing bool
+	failLabels  bool
+	failDeploys bool
+	labels      []string
+}
+
+type fakeComment struct {
+	Id      int       `json:"id"`
+	Body    string    `json:"body"`
+	Created time.Time `json:"created_at"`
+}
+
+func newFakeGitHub() *fakeGitHub {
+	f := &fakeGitHub{}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+// newFakeGitHubTLS is a fakeGitHub signed by its own CA, like a GitHub Enterprise behind a company CA
+func newFakeGitHubTLS() *fakeGitHub {
+	f := &fakeGitHub{}
+	f.server = httptest.NewTLSServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+func (f *fakeGitHub) serve(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	raw, _ := io.ReadAll(r.Body)
+	body := map[string]interface{}{}
+	_ = json.Unmarshal(raw, &body)
+	f.calls = append(f.calls, apiCall{method: r.Method, path: r.URL.Path, authorization: r.Header.Get("Authorization"), body: body})
+
+	w.Header().Set("Content-Type", "application/json")
+	if strings.HasPrefix(r.URL.Path, "/api/v3/repos/kube-cicd/bakery/issues/4/labels") {
+		f.serveLabels(w, r, raw)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/v3/repos/kube-cicd/bakery/issues/") {
+		f.serveComments(w, r, body)
+		return
+	}
+	switch r.URL.Path {
+	case "/api/v3/repos/kube-cicd/bakery/installation":
+		_, _ = w.Write([]byte(`{"id": 42}`))
+	case "/api/v3/app/installations/42/access_tokens":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      "ghs_bakery",
+			"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+	case "/api/graphql":
+		f.minimized = append(f.minimized, body["variables"].(map[string]interface{})["id"].(string))
+		_, _ = w.Write([]byte(`{"data": {"minimizeComment": {"minimizedComment": {"isMinimized": true}}}}`))
+	case "/api/v3/repos/kube-cicd/bakery/statuses/76ea7c7", "/api/v3/repos/kube-cicd/bakery/statuses/161bbb2":
+		_, _ = w.Write([]byte(`{"id": 1, "state": "pending", "context": "bread-pipeline"}`))
+	case "/api/v3/repos/kube-cicd/bakery/commits/76ea7c7":
+		_, _ = w.Write([]byte(`{"sha": "76ea7c7", "commit": {"message": "Knead the dough\n\nlonger", "author": {"name": "Emma Goldman"}}}`))
+	case "/api/v3/repos/kube-cicd/bakery/pulls/4":
+		_, _ = w.Write([]byte(`{"number": 4, "head": {"ref": "feature/rye"}, "base": {"ref": "main"}}`))
+	case "/api/v3/repos/kube-cicd/bakery/deployments":
+		if f.failDeploys {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 7, "environment": "production"}`))
+	case "/api/v3/repos/kube-cicd/bakery/deployments/7/statuses":
+		_, _ = w.Write([]byte(`{"id": 1, "state": "in_progress"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// serveComments is a minimal Pull Request comments API, stateful across calls
+func (f *fakeGitHub) serveComments(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/kube-cicd/bakery/issues/4/comments":
+		if f.failListing {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.comments)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/kube-cicd/bakery/issues/4/comments":
+		comment := fakeComment{Id: 1001 + len(f.calls), Body: body["body"].(string), Created: time.Now()}
+		f.comments = append(f.comments, comment)
+		_ = json.NewEncoder(w).Encode(comment)
+	case strings.HasPrefix(r.URL.Path, "/api/v3/repos/kube-cicd/bakery/issues/comments/"):
+		for num, comment := range f.comments {
+			if r.URL.Path != fmt.Sprintf("/api/v3/repos/kube-cicd/bakery/issues/comments/%d", comment.Id) {
+				continue
+			}
+			switch r.Method {
+			case http.MethodGet:
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": comment.Id, "node_id": fmt.Sprintf("IC_%d", comment.Id)})
+			case http.MethodPatch:
+				f.comments[num].Body = body["body"].(string)
+				_ = json.NewEncoder(w).Encode(f.comments[num])
+			case http.MethodDelete:
+				f.comments = append(f.comments[:num], f.comments[num+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// serveLabels is a minimal Pull Request labels API, stateful across calls
+func (f *fakeGitHub) serveLabels(w http.ResponseWriter, r *http.Request, raw []byte) {
+	if f.failLabels {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		labels := make([]map[string]string, 0)
+		for _, label := range f.labels {
+			labels = append(labels, map[string]string{"name": label})
+		}
+		_ = json.NewEncoder(w).Encode(labels)
+	case http.MethodPost:
+		var added []string
+		_ = json.Unmarshal(raw, &added)
+		f.labels = append(f.labels, added...)
+		_, _ = w.Write([]byte(`[]`))
+	case http.MethodDelete:
+		for num, label := range f.labels {
+			if r.URL.EscapedPath() == "/api/v3/repos/kube-cicd/bakery/issues/4/labels/"+url.PathEscape(label) {
+				f.labels = append(f.labels[:num], f.labels[num+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func createReceiver(cfg map[string]string) (*jxscm.Receiver, *logging.InternalLogger) {
+	logger := logging.CreateLogger(true)
+	receiver := &jxscm.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.FieldConfigurationProvider{ConfigurationProvider: fake.ConfigurationProvider{Contextual: config.NewData("jxscm", cfg, &fake.NullValidator{}, logger)}},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+		Store:        &store.Operator{Store: store.NewMemory()},
+	})
+	return receiver, logger
+}
+
+func createPrivateKey() string {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func createPipeline(status contract.Status, repoUrl string) contract.PipelineInfo {
+	globalCfg := config.NewData("global", map[string]string{}, &fake.NullValidator{}, logging.CreateLogger(true))
+	return *contract.NewPipelineInfo(
+		contract.JobContext{Commit: "76ea7c7", RepoHttpsUrl: repoUrl, OrganizationName: "kube-cicd", RepositoryName: "bakery"},
+		"team-1",
+		"bread-pipeline",
+		"bread-pipeline-abc",
+		time.Now(),
+		[]contract.PipelineStage{{Name: "bake", Status: status}},
+		labels.Set{},
+		labels.Set{},
+		&globalCfg,
+	)
+}
+
+// createPullRequestPipeline creates a Pipeline run in context of Pull Request #4
+func createPullRequestPipeline(name string, instanceName string, status contract.Status) contract.PipelineInfo {
+	return createPullRequestPipelineAtCommit(name, instanceName, "76ea7c7", status)
+}
+
+func createPullRequestPipelineAtCommit(name string, instanceName string, commit string, status contract.Status) contract.PipelineInfo {
+	return createPullRequestPipelineStartedAt(name, instanceName, commit, time.Now(), status)
+}
+
+func createPullRequestPipelineStartedAt(name string, instanceName string, commit string, started time.Time, status contract.Status) contract.PipelineInfo {
+	scmCtx := contract.JobContext{Commit: commit, PrId: "4", RepoHttpsUrl: "https://github.example.org/kube-cicd/bakery",
+		OrganizationName: "kube-cicd", RepositoryName: "bakery"}
+	return *contract.NewPipelineInfo(scmCtx, "team-1", name, instanceName, started,
+		[]contract.PipelineStage{{Name: "bake", Status: status}}, labels.Set{}, labels.Set{}, &config.Data{})
+}
+
+func TestReceiver_AuthenticatesAsGitHubApp(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":               "github",
+		"git-server":             github.server.URL,
+		"github-app-id":          "161",
+		"github-app-private-key": createPrivateKey(),
+		"fetch-git-metadata":     "false",
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning, "https://github.example.org/kube-cicd/bakery"), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineSucceeded, "https://github.example.org/kube-cicd/bakery"), logger))
+
+	// installation token is created once, then taken from the store
+	assert.Len(t, github.calls, 4)
+	assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/installation", github.calls[0].path)
+	assert.Equal(t, "/api/v3/app/installations/42/access_tokens", github.calls[1].path)
+	for _, call := range github.calls[2:] {
+		assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/statuses/76ea7c7", call.path)
+		assert.Equal(t, "Bearer ghs_bakery", call.authorization)
+	}
+}
+
+func TestReceiver_ResolvesCredentialsOncePerReconciliation(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+
+	logger := logging.CreateLogger(true)
+	provider := &fake.FieldConfigurationProvider{ConfigurationProvider: fake.ConfigurationProvider{Contextual: config.NewData("jxscm", map[string]string{
+		"git-kind":               "github",
+		"git-server":             github.server.URL,
+		"github-app-id":          "161",
+		"github-app-private-key": createPrivateKey(),
+		"fetch-git-metadata":     "false",
+	}, &fake.NullValidator{}, logger)}}
+	receiver := &jxscm.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       provider,
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+		Store:        &store.Operator{Store: store.NewMemory()},
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineRunning), logger))
+	fetchesPerResolution := provider.Fetches
+	assert.Greater(t, fetchesPerResolution, 0)
+
+	// the PR comment and next reconciliations reuse already resolved credentials
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineSucceeded), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineFailed), logger))
+	assert.Equal(t, fetchesPerResolution, provider.Fetches)
+}
+
+func TestReceiver_GitHubAppIsNotSupportedForOtherSCMs(t *testing.T) {
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":               "gitlab",
+		"github-app-id":          "161",
+		"github-app-private-key": createPrivateKey(),
+	})
+
+	err := receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning, "https://github.example.org/kube-cicd/bakery"), logger)
+	assert.ErrorContains(t, err, "GitHub App authentication is not supported for git-kind 'gitlab'")
+}
+
+func TestReceiver_SelectsCredentialsByRepositoryHost(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	githubHost := strings.TrimPrefix(github.server.URL, "http://")
+
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "gitlab",
+		"token":              "glpat-bakery",
+		"fetch-git-metadata": "false",
+		"hosts": `
+- host: gitlab.example.org
+  token: glpat-other
+- host: ` + githubHost + `
+  token: ghp_bakery
+`,
+	})
+
+	// git-kind is guessed from the host name, which is unknown for 127.0.0.1, so it has to be set explicitly
+	err := receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning, github.server.URL+"/kube-cicd/bakery"), logger)
+	assert.ErrorContains(t, err, "cannot guess 'git-kind' of '"+githubHost+"'")
+
+	receiver, logger = createReceiver(map[string]string{
+		"git-kind":           "gitlab",
+		"token":              "glpat-bakery",
+		"fetch-git-metadata": "false",
+		"hosts": `
+- host: ` + githubHost + `
+  git-kind: github
+  token: ghp_bakery
+`,
+	})
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning, github.server.URL+"/kube-cicd/bakery"), logger))
+
+	// git-kind set explicitly for the same server is preferred over guessing
+	receiver, logger = createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_default",
+		"fetch-git-metadata": "false",
+		"hosts": `
+- host: ` + githubHost + `
+  token: ghp_bakery
+`,
+	})
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning, github.server.URL+"/kube-cicd/bakery"), logger))
+
+	assert.Len(t, github.calls, 2)
+	for _, call := range github.calls {
+		assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/statuses/76ea7c7", call.path)
+		assert.Equal(t, "Bearer ghp_bakery", call.authorization)
+	}
+}
+
+func TestReceiver_RejectsInvalidHostsTable(t *testing.T) {
+	receiver, logger := createReceiver(map[string]string{
+		"hosts": "- git-kind: gitlab",
+	})
+
+	err := receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning, "https://gitlab.example.org/kube-cicd/bakery"), logger)
+	assert.ErrorContains(t, err, "'jxscm.hosts' entry #1 has no 'host'")
+}
+
+func TestReceiver_TrustsCaBundle(t *testing.T) {
+	github := newFakeGitHubTLS()
+	defer github.server.Close()
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: github.server.Certificate().Raw}))
+	pipeline := createPipeline(contract.PipelineRunning, "https://github.example.org/kube-cicd/bakery")
+
+	untrusted, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"fetch-git-metadata": "false",
+	})
+	assert.NotNil(t, untrusted.UpdateProgress(context.TODO(), pipeline, logger))
+
+	trusted, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"ca-bundle":          caBundle,
+		"fetch-git-metadata": "false",
+	})
+	assert.Nil(t, trusted.UpdateProgress(context.TODO(), pipeline, logger))
+	assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/statuses/76ea7c7", github.calls[len(github.calls)-1].path)
+}
+
+func TestReceiver_TlsSettingsAreScopedToHost(t *testing.T) {
+	github := newFakeGitHubTLS()
+	defer github.server.Close()
+	githubHost := strings.TrimPrefix(github.server.URL, "https://")
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: github.server.Certificate().Raw}))
+	pipeline := createPipeline(contract.PipelineRunning, github.server.URL+"/kube-cicd/bakery")
+
+	// the top-level CA bundle is not inherited by the host entry
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "gitlab",
+		"token":              "glpat-bakery",
+		"ca-bundle":          caBundle,
+		"fetch-git-metadata": "false",
+		"hosts": `
+- host: ` + githubHost + `
+  git-kind: github
+  token: ghp_bakery
+`,
+	})
+	assert.ErrorContains(t, receiver.UpdateProgress(context.TODO(), pipeline, logger), "certificate")
+
+	receiver, logger = createReceiver(map[string]string{
+		"git-kind":           "gitlab",
+		"token":              "glpat-bakery",
+		"fetch-git-metadata": "false",
+		"hosts": `
+- host: ` + githubHost + `
+  git-kind: github
+  token: ghp_bakery
+  ca-bundle: |
+` + indent(caBundle, "    "),
+	})
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), pipeline, logger))
+	assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/statuses/76ea7c7", github.calls[len(github.calls)-1].path)
+}
+
+// indent prefixes every line, so the text can be embedded in YAML as a block
+func indent(text string, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	return prefix + strings.Join(lines, "\n"+prefix) + "\n"
+}
+
+func TestReceiver_RequiresBothClientCertAndKey(t *testing.T) {
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "gitlab",
+		"token":              "glpat-bakery",
+		"client-cert":        "-----BEGIN CERTIFICATE-----",
+		"fetch-git-metadata": "false",
+	})
+
+	err := receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning, "https://gitlab.example.org/kube-cicd/bakery"), logger)
+
+	assert.Contains(t, err.Error(), "both 'jxscm.client-cert' and 'jxscm.client-key' must be specified to use mTLS")
+}
+
+func TestReceiver_SendsStatusPerStage_OnlyWhenChanged(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"stage-statuses":     "true",
+		"fetch-git-metadata": "false",
+	})
+	createStagedPipeline := func(knead contract.Status, bake contract.Status) contract.PipelineInfo {
+		pipeline := createPipeline(contract.PipelineRunning, "https://github.example.org/kube-cicd/bakery")
+		return *contract.NewPipelineInfo(pipeline.GetSCMContext(), pipeline.GetNamespace(), "bread-pipeline", pipeline.GetInstanceName(),
+			pipeline.GetDateStarted(), []contract.PipelineStage{{Name: "knead", Status: knead}, {Name: "bake", Status: bake}},
+			labels.Set{}, labels.Set{}, &config.Data{})
+	}
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createStagedPipeline(contract.PipelineRunning, contract.PipelinePending), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createStagedPipeline(contract.PipelineSucceeded, contract.PipelinePending), logger))
+
+	var contexts []string
+	for _, call := range github.calls {
+		contexts = append(contexts, call.body["context"].(string)+"="+call.body["state"].(string))
+	}
+	assert.Equal(t, []string{
+		"team-1/bread-pipeline=pending",
+		"ci/knead=pending",
+		"ci/bake=pending",
+		"team-1/bread-pipeline=pending",
+		"ci/knead=success",
+	}, contexts)
+}
+
+func TestReceiver_CommitStatusContextIsStableAcrossReruns(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"status-context":     "bakery/{{ .pipeline.GetName }}",
+		"status-description": "{{ .pipeline.GetInstanceName }}: {{ .pipeline.GetStatus }}",
+		"fetch-git-metadata": "false",
+	})
+	firstRun := createPipeline(contract.PipelineFailed, "https://github.example.org/kube-cicd/bakery")
+	rerun := *contract.NewPipelineInfo(firstRun.GetSCMContext(), "team-1", "bread-pipeline", "bread-pipeline-xyz", time.Now(),
+		[]contract.PipelineStage{{Name: "bake", Status: contract.PipelineSucceeded}}, labels.Set{}, labels.Set{}, &config.Data{})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), firstRun, logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), rerun, logger))
+
+	assert.Equal(t, "bakery/team-1/bread-pipeline", github.calls[0].body["context"])
+	assert.Equal(t, "bread-pipeline-abc: failed", github.calls[0].body["description"])
+	assert.Equal(t, "bakery/team-1/bread-pipeline", github.calls[1].body["context"])
+	assert.Equal(t, "bread-pipeline-xyz: succeeded", github.calls[1].body["description"])
+}
+
+func TestReceiver_AggregatedCommentHasSectionPerPipeline(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":              "github",
+		"git-server":            github.server.URL,
+		"token":                 "ghp_bakery",
+		"progress-comment-mode": "aggregated",
+		"fetch-git-metadata":    "false",
+	})
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineRunning), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("croissant", "croissant-abc", contract.PipelineFailed), logger))
+	// a rerun replaces the section of the previous run
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("croissant", "croissant-xyz", contract.PipelineSucceeded), logger))
+
+	assert.Len(t, github.comments, 1)
+	body := github.comments[0].Body
+	assert.Contains(t, body, "team-1/bread (bread-abc)")
+	assert.Contains(t, body, "team-1/croissant (croissant-xyz)")
+	assert.NotContains(t, body, "croissant-abc")
+	assert.Less(t, strings.Index(body, "team-1/bread"), strings.Index(body, "team-1/croissant"))
+	assert.Contains(t, body, "(pfc-pr=4/updateAggregatedPRComment)")
+}
+
+func createReceiverWithPreviousRuns(mode string) (*jxscm.Receiver, *logging.InternalLogger, *fakeGitHub) {
+	github := newFakeGitHub()
+	created := time.Now().Add(-time.Hour)
+	github.comments = []fakeComment{
+		{Id: 1, Body: "Baking... (pfc-id=team-1/bread/bread-abc/updatePRStatusComment)", Created: created},
+		{Id: 2, Body: "Failed (pfc-id=team-1/bread/bread-abc/WhenFinished)", Created: created},
+		{Id: 3, Body: "Baking... (pfc-id=team-1/croissant/croissant-abc/updatePRStatusComment)", Created: created},
+		{Id: 4, Body: "Baking... (pfc-id=team-1/bread-with-butter/bread-with-butter-abc/updatePRStatusComment)", Created: created},
+	}
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":            "github",
+		"git-server":          github.server.URL,
+		"token":               "ghp_bakery",
+		"superseded-comments": mode,
+		"fetch-git-metadata":  "false",
+	})
+	return receiver, logger, github
+}
+
+func TestReceiver_CollapsesCommentsOfPreviousRuns(t *testing.T) {
+	receiver, logger, github := createReceiverWithPreviousRuns("collapse")
+	defer github.server.Close()
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-xyz", contract.PipelineRunning), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-xyz", contract.PipelineSucceeded), logger))
+
+	for _, comment := range github.comments[0:2] {
+		assert.Equal(t, 1, strings.Count(comment.Body, "Superseded by run bread-xyz"), "should be collapsed once")
+		assert.True(t, strings.HasPrefix(comment.Body, "<details>"))
+	}
+	assert.Equal(t, "Baking... (pfc-id=team-1/croissant/croissant-abc/updatePRStatusComment)", github.comments[2].Body)
+	assert.Equal(t, "Baking... (pfc-id=team-1/bread-with-butter/bread-with-butter-abc/updatePRStatusComment)", github.comments[3].Body)
+	assert.Contains(t, github.comments[4].Body, "(pfc-id=team-1/bread/bread-xyz/updatePRStatusComment)", "new run has its own comment")
+	assert.NotContains(t, github.comments[0].Body, "(pfc-id=team-1/bread/bread-abc/updatePRStatusComment)", "marking of the old run is neutralized")
+}
+
+func TestReceiver_PreviousRunDoesNotUpdateItsCollapsedComment(t *testing.T) {
+	receiver, logger, github := createReceiverWithPreviousRuns("collapse")
+	defer github.server.Close()
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-xyz", contract.PipelineRunning), logger))
+	collapsed := github.comments[0].Body
+	commentsCount := len(github.comments)
+
+	// the old run is still running, and reports its progress after the new run has collapsed its comment
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineFailed), logger))
+
+	// the same with a lost store, the collapsed comment is recognized by its neutralized marking
+	restarted, logger := createReceiver(map[string]string{
+		"git-kind":            "github",
+		"git-server":          github.server.URL,
+		"token":               "ghp_bakery",
+		"superseded-comments": "collapse",
+		"fetch-git-metadata":  "false",
+	})
+	assert.Nil(t, restarted.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineSucceeded), logger))
+
+	assert.Equal(t, collapsed, github.comments[0].Body, "collapsed comment is not overwritten")
+	assert.Len(t, github.comments, commentsCount, "no new comment is created for the old run")
+	assert.NotContains(t, github.comments[4].Body, "Superseded", "the old run does not collapse comments of the new run")
+}
+
+func TestReceiver_DeletesCommentsOfPreviousRuns(t *testing.T) {
+	receiver, logger, github := createReceiverWithPreviousRuns("delete")
+	defer github.server.Close()
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-xyz", contract.PipelineRunning), logger))
+
+	assert.Len(t, github.comments, 3)
+	assert.Equal(t, 3, github.comments[0].Id)
+	assert.Equal(t, 4, github.comments[1].Id)
+}
+
+func TestReceiver_MinimizesCommentsOfPreviousRunsOnGitHub(t *testing.T) {
+	receiver, logger, github := createReceiverWithPreviousRuns("minimize")
+	defer github.server.Close()
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-xyz", contract.PipelineRunning), logger))
+
+	assert.Equal(t, []string{"IC_2", "IC_1"}, github.minimized, "newest comments are visited first")
+	assert.Equal(t, "Baking... (pfc-id=team-1/bread/bread-abc/updatePRStatusComment)", github.comments[0].Body)
+
+	// a later run does not minimize the same comments again
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-qwe", contract.PipelineRunning), logger))
+	assert.Len(t, github.minimized, 3, "only the comment of 'bread-xyz' is minimized")
+	assert.NotContains(t, []string{"IC_1", "IC_2"}, github.minimized[2], "already minimized comments are skipped")
+}
+
+func TestReceiver_OlderRunDoesNotSupersedeCommentsOfNewerRun(t *testing.T) {
+	for _, mode := range []string{"collapse", "minimize", "delete"} {
+		receiver, logger, github := createReceiverWithPreviousRuns(mode)
+		assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-xyz", contract.PipelineRunning), logger))
+		newest := github.comments[len(github.comments)-1]
+
+		// a run started before 'bread-xyz' reconciles late, after the controller was restarted with an empty store
+		older, logger := createReceiver(map[string]string{
+			"git-kind":            "github",
+			"git-server":          github.server.URL,
+			"token":               "ghp_bakery",
+			"superseded-comments": mode,
+			"fetch-git-metadata":  "false",
+		})
+		assert.Nil(t, older.UpdateProgress(context.TODO(), createPullRequestPipelineStartedAt("bread", "bread-old", "76ea7c7",
+			time.Now().Add(-time.Minute), contract.PipelineRunning), logger))
+
+		assert.Contains(t, github.comments, newest, mode)
+		assert.NotContains(t, github.minimized, "IC_"+strconv.Itoa(newest.Id), mode)
+		github.server.Close()
+	}
+}
+
+func TestReceiver_EditsProgressCommentByRecordedId(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"fetch-git-metadata": "false",
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineRunning), logger))
+	// the same status is not sent again
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineRunning), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineSucceeded), logger))
+
+	var commentCalls []string
+	for _, call := range github.calls {
+		if strings.Contains(call.path, "/issues/") {
+			commentCalls = append(commentCalls, call.method+" "+call.path)
+		}
+	}
+	assert.Equal(t, []string{
+		"GET /api/v3/repos/kube-cicd/bakery/issues/4/comments",
+		"POST /api/v3/repos/kube-cicd/bakery/issues/4/comments",
+		fmt.Sprintf("PATCH /api/v3/repos/kube-cicd/bakery/issues/comments/%d", github.comments[0].Id),
+	}, commentCalls)
+	assert.Len(t, github.comments, 1)
+	assert.Contains(t, github.comments[0].Body, ":white_check_mark:")
+}
+
+func TestReceiver_DoesNotCreateDuplicatedCommentWhenSearchFails(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	github.failListing = true
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"fetch-git-metadata": "false",
+	})
+
+	err := receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineRunning), logger)
+
+	assert.Contains(t, err.Error(), "cannot search for an existing comment in a Pull Request")
+	assert.Empty(t, github.comments)
+}
+
+func TestReceiver_LabelsPullRequestWithAggregatedStatus(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	github.labels = []string{"bug", "ci/passed"}
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"pr-labels":          "true",
+		"pr-label-failed":    "ci/burnt",
+		"fetch-git-metadata": "false",
+	})
+	update := func(name string, instanceName string, status contract.Status) {
+		assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline(name, instanceName, status), logger))
+	}
+
+	update("bread", "bread-abc", contract.PipelineRunning)
+	assert.Equal(t, []string{"bug", "ci/running"}, github.labels)
+
+	update("croissant", "croissant-abc", contract.PipelineFailed)
+	update("bread", "bread-abc", contract.PipelineSucceeded)
+	assert.Equal(t, []string{"bug", "ci/burnt"}, github.labels, "one failed Pipeline fails the whole PR")
+
+	// a rerun of the failed Pipeline replaces its previous status
+	update("croissant", "croissant-xyz", contract.PipelineRunning)
+	assert.Equal(t, []string{"bug", "ci/running"}, github.labels)
+	update("croissant", "croissant-xyz", contract.PipelineSucceeded)
+	assert.Equal(t, []string{"bug", "ci/passed"}, github.labels)
+
+	// after a push only Pipelines of the new commit count
+	update("croissant", "croissant-qwe", contract.PipelineFailed)
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipelineAtCommit("bread", "bread-qwe", "161bbb2", contract.PipelineSucceeded), logger))
+	assert.Equal(t, []string{"bug", "ci/passed"}, github.labels)
+}
+
+func TestReceiver_LabelErrorsDoNotFailTheUpdate(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	github.failLabels = true
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"pr-labels":          "true",
+		"fetch-git-metadata": "false",
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineRunning), logger))
+	assert.Len(t, github.comments, 1, "the progress comment is still created")
+}
+
+// createDeployPipeline creates a Pipeline annotated with the "production" environment
+func createDeployPipeline(status contract.Status) contract.PipelineInfo {
+	pipeline := createPipeline(status, "https://github.example.org/kube-cicd/bakery")
+	scmCtx := pipeline.GetSCMContext()
+	scmCtx.Environment = "production"
+	scmCtx.EnvironmentUrl = "https://bakery.example.org"
+	return *contract.NewPipelineInfo(scmCtx, "team-1", "bread-pipeline", "bread-pipeline-abc", time.Now(),
+		pipeline.GetStages(), labels.Set{}, labels.Set{}, &config.Data{})
+}
+
+func TestReceiver_ReportsDeploymentToEnvironment(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"deployments":        "true",
+		"fetch-git-metadata": "false",
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createDeployPipeline(contract.PipelineRunning), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createDeployPipeline(contract.PipelineRunning), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createDeployPipeline(contract.PipelineSucceeded), logger))
+
+	var deploymentCalls []apiCall
+	for _, call := range github.calls {
+		if strings.Contains(call.path, "/deployments") {
+			deploymentCalls = append(deploymentCalls, call)
+		}
+	}
+	assert.Len(t, deploymentCalls, 3, "the deployment is created once, a status is sent only when it changes")
+	assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/deployments", deploymentCalls[0].path)
+	assert.Equal(t, "76ea7c7", deploymentCalls[0].body["ref"])
+	assert.Equal(t, "production", deploymentCalls[0].body["environment"])
+	assert.Equal(t, []interface{}{}, deploymentCalls[0].body["required_contexts"])
+	assert.Equal(t, "in_progress", deploymentCalls[1].body["state"])
+	assert.Equal(t, "https://bakery.example.org", deploymentCalls[1].body["environment_url"])
+	assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/deployments/7/statuses", deploymentCalls[2].path)
+	assert.Equal(t, "success", deploymentCalls[2].body["state"])
+}
+
+func TestReceiver_DoesNotReportDeploymentByDefault(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"fetch-git-metadata": "false",
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createDeployPipeline(contract.PipelineRunning), logger))
+	assert.Len(t, github.calls, 1, "only the commit status is sent")
+}
+
+func TestReceiver_DeploymentErrorsDoNotFailTheUpdate(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	github.failDeploys = true
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"deployments":        "true",
+		"fetch-git-metadata": "false",
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createDeployPipeline(contract.PipelineRunning), logger))
+	assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/statuses/76ea7c7", github.calls[0].path, "the commit status is still sent")
+}
+
+func TestReceiver_FinalDeploymentStatusIsRetried(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	github.failDeploys = true
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"deployments":        "true",
+		"fetch-git-metadata": "false",
+	})
+
+	err := receiver.UpdateProgress(context.TODO(), createDeployPipeline(contract.PipelineSucceeded), logger)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot report deployment to 'production'")
+
+	// the SCM is back, the retried reconciliation delivers the final status
+	github.failDeploys = false
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createDeployPipeline(contract.PipelineSucceeded), logger))
+	last := github.calls[len(github.calls)-1]
+	assert.Equal(t, "/api/v3/repos/kube-cicd/bakery/deployments/7/statuses", last.path)
+	assert.Equal(t, "success", last.body["state"])
+}
+
+func TestReceiver_FillsMissingGitMetadataOncePerCommit(t *testing.T) {
+	github := newFakeGitHub()
+	defer github.server.Close()
+	receiver, logger := createReceiver(map[string]string{
+		"git-kind":           "github",
+		"git-server":         github.server.URL,
+		"token":              "ghp_bakery",
+		"fetch-git-metadata": "true",
+		"status-description": "{{ with .pipeline.GetSCMContext }}{{ .CommitAuthor }}: {{ .CommitTitle }} ({{ .SourceBranch }} -> {{ .TargetBranch }}){{ end }}",
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineRunning), logger))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPullRequestPipeline("bread", "bread-abc", contract.PipelineSucceeded), logger))
+
+	var descriptions []interface{}
+	fetches := 0
+	for _, call := range github.calls {
+		if strings.HasSuffix(call.path, "/statuses/76ea7c7") {
+			descriptions = append(descriptions, call.body["description"])
+		}
+		if strings.HasSuffix(call.path, "/commits/76ea7c7") || strings.HasSuffix(call.path, "/pulls/4") {
+			fetches++
+		}
+	}
+	assert.Equal(t, []interface{}{
+		"Emma Goldman: Knead the dough (feature/rye -> main)",
+		"Emma Goldman: Knead the dough (feature/rye -> main)",
+	}, descriptions)
+	assert.Equal(t, 2, fetches, "commit and Pull Request are fetched only once")
+}
@@ -0,0 +1,183 @@
+package jxscm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/pkg/errors"
+)
+
+const (
+	supersededKeep     = "keep"
+	supersededCollapse = "collapse"
+	supersededMinimize = "minimize"
+	supersededDelete   = "delete"
+)
+
+const collapsedCommentTemplate = `<details>
+<summary>:fast_forward: Superseded by run %s %s</summary>
+
+%s
+</details>
+`
+
+// supersededMarkingPart is appended to collapsed comments, so those are not collapsed again by later runs
+const supersededMarkingPart = "(pfc-superseded-by="
+
+// supersededIdMarkingPart replaces "(pfc-id=" in collapsed comments, so the superseded run does not find its comment
+// by the marking anymore, while it still can tell that the comment was superseded
+const supersededIdMarkingPart = "(pfc-superseded-id="
+
+// handleSupersededComments collapses, minimizes or deletes the PR comments of previous runs of the same Pipeline,
+// found by markings of the progress and summary comments. Done once per Pipeline run and Pull Request
+func (jx *Receiver) handleSupersededComments(ctx context.Context, cfg config.Data, client *scm.Client, pipeline contract.PipelineInfo,
+	log *logging.InternalLogger) error {
+
+	mode := cfg.GetOrDefault("superseded-comments", supersededKeep)
+	if mode == supersededKeep {
+		return nil
+	}
+	if mode != supersededCollapse && mode != supersededMinimize && mode != supersededDelete {
+		return errors.Errorf("invalid 'jxscm.superseded-comments' value '%s', expected one of: keep, collapse, minimize, delete", mode)
+	}
+	if mode == supersededMinimize && client.Driver != scm.DriverGithub {
+		log.Debugf("Comments can be minimized only on GitHub, collapsing them instead")
+		mode = supersededCollapse
+	}
+	event := "jxscm/superseded-comments/pr-" + pipeline.GetSCMContext().PrId
+	if jx.sc.Store.WasEventAlreadySent(pipeline, event) || jx.sc.Store.IsPipelineRunSuperseded(pipeline) {
+		return nil
+	}
+
+	prId, _ := strconv.Atoi(pipeline.GetSCMContext().PrId)
+	repo := pipeline.GetSCMContext().GetNameWithOrg()
+	comments := make([]*scm.Comment, 0)
+	maxPages, _ := strconv.Atoi(cfg.GetOrDefault("comment-search-max-pages", "20"))
+	listErr := jxscm.WalkPullRequestComments(ctx, client.PullRequests, client.Driver, repo, prId, maxPages, func(comment *scm.Comment) bool {
+		comments = append(comments, comment)
+		return false
+	})
+	if listErr != nil {
+		return listErr
+	}
+	// this run was already superseded by a newer one, then it must not supersede the newer run's comments
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, supersededIdMarkingPart+pipeline.GetId()+"/") {
+			jx.sc.Store.RecordPipelineRunSuperseded(pipeline.GetId(), pipeline.GetSCMContext().PrId)
+			return nil
+		}
+	}
+	repoUrl := pipeline.GetSCMContext().RepoHttpsUrl
+	for _, comment := range findSupersededComments(comments, pipeline) {
+		// minimized comments keep their body, so those are recognized by the store
+		if mode == supersededMinimize && jx.sc.Store.WasCommentMinimized(repoUrl, strconv.Itoa(comment.ID)) {
+			continue
+		}
+		var err error
+		switch mode {
+		case supersededCollapse:
+			body := fmt.Sprintf(collapsedCommentTemplate, pipeline.GetInstanceName(), supersededMarkingPart+pipeline.GetId()+")",
+				strings.ReplaceAll(comment.Body, "(pfc-id=", supersededIdMarkingPart))
+			_, _, err = client.PullRequests.EditComment(ctx, repo, prId, comment.ID, &scm.CommentInput{Body: body})
+		case supersededMinimize:
+			if err = minimizeGitHubComment(ctx, client, repo, comment.ID); err == nil {
+				jx.sc.Store.RecordCommentMinimized(repoUrl, strconv.Itoa(comment.ID))
+			}
+		case supersededDelete:
+			_, err = client.PullRequests.DeleteComment(ctx, repo, prId, comment.ID)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "cannot %s a superseded comment '%d'", mode, comment.ID)
+		}
+		// the previous run may still be running, it must not update or recreate its comments
+		jx.sc.Store.RecordPipelineRunSuperseded(pipeline.GetName()+"/"+comment.instance, pipeline.GetSCMContext().PrId)
+		log.Debugf("Comment '%d' superseded by '%s' (%s)", comment.ID, pipeline.GetId(), mode)
+	}
+	return jx.sc.Store.RecordEventFiring(pipeline, event)
+}
+
+// supersededComment is a comment of a previous run, identified by the run's instance name
+type supersededComment struct {
+	*scm.Comment
+	instance string
+}
+
+// findSupersededComments returns comments created for previous runs of the same Pipeline, skipping already collapsed ones.
+// Comments created after this run was started belong to a newer run, those are never returned
+func findSupersededComments(comments []*scm.Comment, pipeline contract.PipelineInfo) []supersededComment {
+	marking := regexp.MustCompile(`\(pfc-id=` + regexp.QuoteMeta(pipeline.GetName()) + `/([^/)]+)/(updatePRStatusComment|WhenFinished)\)`)
+	started := pipeline.GetDateStarted()
+	found := make([]supersededComment, 0)
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, supersededMarkingPart) {
+			continue
+		}
+		if !started.IsZero() && comment.Created.After(started) {
+			continue
+		}
+		if match := marking.FindStringSubmatch(comment.Body); match != nil && match[1] != pipeline.GetInstanceName() {
+			found = append(found, supersededComment{Comment: comment, instance: match[1]})
+		}
+	}
+	return found
+}
+
+// minimizeGitHubComment hides the comment as outdated. The GraphQL API needs a node id, which is not exposed by go-scm
+func minimizeGitHubComment(ctx context.Context, client *scm.Client, repo string, commentId int) error {
+	comment := struct {
+		NodeId string `json:"node_id"`
+	}{}
+	if err := doJSON(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/issues/comments/%d", repo, commentId), nil, &comment); err != nil {
+		return errors.Wrap(err, "cannot fetch comment node id")
+	}
+
+	result := struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	mutation := map[string]interface{}{
+		"query":     `mutation($id: ID!) { minimizeComment(input: {subjectId: $id, classifier: OUTDATED}) { minimizedComment { isMinimized } } }`,
+		"variables": map[string]string{"id": comment.NodeId},
+	}
+	if err := doJSON(ctx, client, http.MethodPost, client.GraphQLURL.String(), mutation, &result); err != nil {
+		return errors.Wrap(err, "cannot minimize comment")
+	}
+	if len(result.Errors) > 0 {
+		return errors.Errorf("cannot minimize comment: %s", result.Errors[0].Message)
+	}
+	return nil
+}
+
+// doJSON sends a raw request through the authorized go-scm client, for APIs not covered by go-scm
+func doJSON(ctx context.Context, client *scm.Client, method string, path string, in interface{}, out interface{}) error {
+	request := &scm.Request{Method: method, Path: path, Header: http.Header{"Accept": []string{"application/json"}}}
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		request.Body = bytes.NewReader(encoded)
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, err := client.Do(ctx, request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.Status >= 300 {
+		return errors.Errorf("SCM responded with HTTP %d", response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
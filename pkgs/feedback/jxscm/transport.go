@@ -0,0 +1,56 @@
+package jxscm
+
+import (
+	"context"
+
+	"github.com/kube-cicd/pipelines-feedback-core/internal/feedback/jxscm"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/pkg/errors"
+)
+
+// fetchTransportOptions collects TLS and proxy settings of the SCM connection. Each certificate can be specified inline,
+// or referenced from a `kind: Secret` (the CA bundle also from a `kind: ConfigMap`)
+func (jx *Receiver) fetchTransportOptions(ctx context.Context, data config.Data, pipeline contract.PipelineInfo) (jxscm.TransportOptions, error) {
+	opts := jxscm.TransportOptions{
+		InsecureSkipVerify: data.GetOrDefault("insecure-skip-verify", "false") == "true",
+		HttpProxy:          data.Get("http-proxy"),
+		HttpsProxy:         data.Get("https-proxy"),
+		NoProxy:            data.Get("no-proxy"),
+	}
+
+	var err error
+	if data.Get("ca-bundle-configmap-name") != "" {
+		opts.CaBundle, err = jx.sc.Config.FetchConfigMapKey(ctx, data.Get("ca-bundle-configmap-name"), pipeline.GetNamespace(),
+			data.GetOrDefault("ca-bundle-configmap-key", "ca.crt"), true)
+		if err != nil {
+			return opts, errors.Wrap(err, "cannot fetch CA bundle from a `kind: ConfigMap` referenced in 'jxscm.ca-bundle-configmap-name'")
+		}
+	} else if opts.CaBundle, err = jx.fetchOptionalSecret(ctx, data, pipeline, "ca-bundle"); err != nil {
+		return opts, err
+	}
+	if opts.ClientCert, err = jx.fetchOptionalSecret(ctx, data, pipeline, "client-cert"); err != nil {
+		return opts, err
+	}
+	if opts.ClientKey, err = jx.fetchOptionalSecret(ctx, data, pipeline, "client-key"); err != nil {
+		return opts, err
+	}
+	if (opts.ClientCert == "") != (opts.ClientKey == "") {
+		return opts, errors.New("both 'jxscm.client-cert' and 'jxscm.client-key' must be specified to use mTLS")
+	}
+	return opts, nil
+}
+
+// fetchOptionalSecret reads "jxscm.<field>" as plaintext, or from a `kind: Secret` referenced by "jxscm.<field>-secret-name"
+// and "jxscm.<field>-secret-key". Returns an empty string when neither is specified
+func (jx *Receiver) fetchOptionalSecret(ctx context.Context, data config.Data, pipeline contract.PipelineInfo, field string) (string, error) {
+	if data.Get(field) == "" && data.Get(field+"-secret-name") == "" {
+		return "", nil
+	}
+	value, err := jx.sc.Config.FetchFromFieldOrSecret(ctx, &data, pipeline.GetNamespace(), field, field+"-secret-key", field+"-secret-name")
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot fetch '%s' neither from 'jxscm.%s' as plaintext neither from a `kind: Secret` referenced in 'jxscm.%s-secret-name'",
+			field, field, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,59 @@
+package messagebus
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/pkg/errors"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+)
+
+// kafkaPublisher produces records with an idempotent producer, waiting for all in-sync replicas.
+// Records are keyed by the Pipeline id, so events of a single Pipeline are kept in order on a single partition
+type kafkaPublisher struct {
+	client *kgo.Client
+}
+
+func newKafkaPublisher(settings connectionSettings) (*kafkaPublisher, error) {
+	options := []kgo.Opt{
+		kgo.SeedBrokers(strings.Split(settings.servers, ",")...),
+		kgo.ClientID("pipelines-feedback"),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordDeliveryTimeout(settings.timeout),
+	}
+	if settings.username != "" {
+		options = append(options, kgo.SASL(plain.Auth{User: settings.username, Pass: settings.password}.AsMechanism()))
+	}
+	if settings.tls {
+		options = append(options, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	client, err := kgo.NewClient(options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create Kafka client")
+	}
+	return &kafkaPublisher{client: client}, nil
+}
+
+func (k *kafkaPublisher) publish(ctx context.Context, topic string, msg Message, log *logging.InternalLogger) error {
+	data, err := msg.encode()
+	if err != nil {
+		return err
+	}
+	record := &kgo.Record{
+		Topic:   topic,
+		Key:     []byte(msg.Id),
+		Value:   data,
+		Headers: []kgo.RecordHeader{{Key: IdempotencyKeyHeader, Value: []byte(msg.IdempotencyKey)}},
+	}
+	if err := k.client.ProduceSync(ctx, record).FirstErr(); err != nil {
+		return errors.Wrapf(err, "cannot produce to Kafka topic '%s'", topic)
+	}
+	return nil
+}
+
+func (k *kafkaPublisher) close() {
+	k.client.Close()
+}
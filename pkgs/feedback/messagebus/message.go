@@ -0,0 +1,41 @@
+package messagebus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/webhook"
+	"github.com/pkg/errors"
+)
+
+// IdempotencyKeyHeader is a message header that allows the consumers to drop redelivered messages
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Message is a JSON document published on the bus. Same document as sent by the webhook receiver, plus the idempotency key
+type Message struct {
+	webhook.Payload
+	IdempotencyKey string `json:"idempotencyKey"`
+}
+
+// NewMessage is a constructor
+func NewMessage(event string, pipeline contract.PipelineInfo) Message {
+	return Message{
+		Payload:        webhook.NewPayload(event, pipeline),
+		IdempotencyKey: CreateIdempotencyKey(event, pipeline),
+	}
+}
+
+// CreateIdempotencyKey returns the same key as long as the Pipeline is in the same state. A redelivery
+// of already published state (e.g. after a failure of other receiver or a controller restart) results in the same key
+func CreateIdempotencyKey(event string, pipeline contract.PipelineInfo) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(pipeline.GetId() + "\n" + event + "\n" + pipeline.ToHash()))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func (m Message) encode() ([]byte, error) {
+	encoded, err := json.Marshal(m)
+	return encoded, errors.Wrap(err, "cannot encode a message")
+}
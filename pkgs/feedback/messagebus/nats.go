@@ -0,0 +1,73 @@
+package messagebus
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+)
+
+// natsPublisher publishes to a JetStream stream and waits for an acknowledgement. JetStream deduplicates
+// messages by "Nats-Msg-Id" in the stream's duplicate window. Core NATS does not acknowledge, it only flushes the connection
+type natsPublisher struct {
+	conn      *nats.Conn
+	jetStream jetstream.JetStream
+}
+
+func newNatsPublisher(settings connectionSettings) (*natsPublisher, error) {
+	options := []nats.Option{nats.Name("pipelines-feedback"), nats.Timeout(settings.timeout)}
+	if settings.username != "" {
+		options = append(options, nats.UserInfo(settings.username, settings.password))
+	}
+	if settings.tls {
+		options = append(options, nats.Secure(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	conn, err := nats.Connect(settings.servers, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot connect to NATS")
+	}
+
+	publisher := &natsPublisher{conn: conn}
+	if settings.jetStream {
+		js, jsErr := jetstream.New(conn)
+		if jsErr != nil {
+			conn.Close()
+			return nil, errors.Wrap(jsErr, "cannot create JetStream context")
+		}
+		publisher.jetStream = js
+	}
+	return publisher, nil
+}
+
+func (n *natsPublisher) publish(ctx context.Context, subject string, msg Message, log *logging.InternalLogger) error {
+	data, err := msg.encode()
+	if err != nil {
+		return err
+	}
+	natsMsg := nats.NewMsg(subject)
+	natsMsg.Data = data
+	natsMsg.Header.Set(IdempotencyKeyHeader, msg.IdempotencyKey)
+
+	if n.jetStream == nil {
+		if err := n.conn.PublishMsg(natsMsg); err != nil {
+			return errors.Wrapf(err, "cannot publish to NATS subject '%s'", subject)
+		}
+		return errors.Wrap(n.conn.FlushWithContext(ctx), "cannot flush NATS connection")
+	}
+
+	ack, err := n.jetStream.PublishMsg(ctx, natsMsg, jetstream.WithMsgID(msg.IdempotencyKey), jetstream.WithRetryAttempts(3))
+	if err != nil {
+		return errors.Wrapf(err, "cannot publish to JetStream subject '%s'", subject)
+	}
+	if ack.Duplicate {
+		log.Debugf("JetStream stream '%s' already contains message '%s'", ack.Stream, msg.IdempotencyKey)
+	}
+	return nil
+}
+
+func (n *natsPublisher) close() {
+	n.conn.Close()
+}
@@ -0,0 +1,212 @@
+package messagebus
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/webhook"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
+	"github.com/pkg/errors"
+)
+
+const (
+	DriverNats  = "nats"
+	DriverKafka = "kafka"
+)
+
+const defaultSubject = `pipelines.{{ .pipeline.GetNamespace }}.{{ with .pipeline.GetSCMContext.RepositoryName }}{{ . }}{{ else }}none{{ end }}`
+
+type publisher interface {
+	publish(ctx context.Context, subject string, msg Message, log *logging.InternalLogger) error
+	close()
+}
+
+// endpoint identifies a connection, a connection with changed settings (e.g. a rotated password) replaces the previous one
+type endpoint struct {
+	driver  string
+	servers string
+}
+
+type connectionSettings struct {
+	driver    string
+	servers   string
+	username  string
+	password  string
+	tls       bool
+	jetStream bool
+	timeout   time.Duration
+}
+
+// Receiver publishes Pipeline state changes to a NATS subject or a Kafka topic. The delivery is at-least-once:
+// a failed publishing fails the reconciliation, which is then retried. Each message carries an idempotency key
+type Receiver struct {
+	sc *wiring.ServiceContext
+
+	// connections are kept open between reconciliations, one per endpoint
+	connections map[endpoint]connection
+	mutex       sync.Mutex
+}
+
+// connection is a publisher together with the settings it was opened with
+type connection struct {
+	settings  connectionSettings
+	publisher publisher
+}
+
+func (mb *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing Message Bus Receiver")
+	mb.sc = sc
+	mb.connections = make(map[endpoint]connection)
+
+	// register configuration options
+	mb.sc.ConfigSchema.Add(config.Schema{
+		Name: "messagebus",
+		AllowedFields: []string{
+			"driver",
+			"servers",
+			"subject",
+			"events",
+			"jetstream",
+			"username",
+			"password",
+			"password-secret-name",
+			"password-secret-key",
+			"tls",
+			"timeout",
+		},
+	})
+	return nil
+}
+
+func (mb *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return mb.publish(ctx, webhook.EventCreated, pipeline, log)
+}
+
+func (mb *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return mb.publish(ctx, webhook.EventStarted, pipeline, log)
+}
+
+func (mb *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return mb.publish(ctx, webhook.EventProgress, pipeline, log)
+}
+
+func (mb *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return mb.publish(ctx, webhook.EventFinished, pipeline, log)
+}
+
+func (mb *Receiver) publish(ctx context.Context, event string, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := mb.sc.Config.FetchContextual("messagebus", pipeline.GetNamespace(), pipeline)
+	if cfg.Get("servers") == "" {
+		log.Debugf("Skipping message bus, 'messagebus.servers' is not configured for '%s'", pipeline.GetId())
+		return nil
+	}
+	if !webhook.IsEventEnabled(cfg.GetOrDefault("events", "created,started,progress,finished"), event) {
+		return nil
+	}
+
+	settings, err := mb.createConnectionSettings(ctx, cfg, pipeline)
+	if err != nil {
+		return err
+	}
+	subject, err := templating.TemplateChatMessage(cfg.GetOrDefault("subject", defaultSubject), "messagebus-subject", pipeline,
+		map[string]interface{}{"event": event})
+	if err != nil {
+		return errors.Wrap(err, "cannot render 'messagebus.subject'")
+	}
+	conn, err := mb.getConnection(settings)
+	if err != nil {
+		return err
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, settings.timeout)
+	defer cancel()
+	msg := NewMessage(event, pipeline)
+	if err := conn.publish(publishCtx, strings.TrimSpace(subject), msg, log); err != nil {
+		return errors.Wrapf(err, "cannot publish '%s' event", event)
+	}
+	log.Debugf("Published '%s' event of '%s' to '%s', idempotency key: %s", event, pipeline.GetId(), subject, msg.IdempotencyKey)
+	return nil
+}
+
+func (mb *Receiver) createConnectionSettings(ctx context.Context, cfg config.Data, pipeline contract.PipelineInfo) (connectionSettings, error) {
+	driver := cfg.GetOrDefault("driver", DriverNats)
+	if driver != DriverNats && driver != DriverKafka {
+		return connectionSettings{}, errors.New("invalid 'messagebus.driver', expected 'nats' or 'kafka'")
+	}
+	timeout, err := time.ParseDuration(cfg.GetOrDefault("timeout", "10s"))
+	if err != nil {
+		return connectionSettings{}, errors.Wrap(err, "invalid 'messagebus.timeout'")
+	}
+
+	// "messagebus.password" as plaintext, or a `kind: Secret` referenced by "messagebus.password-secret-name" and "messagebus.password-secret-key"
+	password := ""
+	if cfg.Get("username") != "" {
+		password, err = mb.sc.Config.FetchFromFieldOrSecret(ctx, &cfg, pipeline.GetNamespace(), "password", "password-secret-key", "password-secret-name")
+		if err != nil {
+			return connectionSettings{}, errors.Wrap(err, "cannot fetch password neither from 'messagebus.password' as plaintext neither from a `kind: Secret` referenced in 'messagebus.password-secret-name'")
+		}
+	}
+
+	return connectionSettings{
+		driver:    driver,
+		servers:   cfg.Get("servers"),
+		username:  cfg.Get("username"),
+		password:  password,
+		tls:       cfg.GetOrDefault("tls", "false") == "true",
+		jetStream: cfg.GetOrDefault("jetstream", "true") == "true",
+		timeout:   timeout,
+	}, nil
+}
+
+// getConnection returns an already open connection, or connects. A connection to the same endpoint opened
+// with other settings is closed first, so e.g. a rotated password does not leave the old connection open
+func (mb *Receiver) getConnection(settings connectionSettings) (publisher, error) {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	key := endpoint{driver: settings.driver, servers: settings.servers}
+	if existing, exists := mb.connections[key]; exists {
+		if existing.settings == settings {
+			return existing.publisher, nil
+		}
+		existing.publisher.close()
+		delete(mb.connections, key)
+	}
+	var conn publisher
+	var err error
+	if settings.driver == DriverKafka {
+		conn, err = newKafkaPublisher(settings)
+	} else {
+		conn, err = newNatsPublisher(settings)
+	}
+	if err != nil {
+		return nil, err
+	}
+	mb.connections[key] = connection{settings: settings, publisher: conn}
+	return conn, nil
+}
+
+// Close closes all open connections
+func (mb *Receiver) Close() {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	for key, conn := range mb.connections {
+		conn.publisher.close()
+		delete(mb.connections, key)
+	}
+}
+
+func (mb *Receiver) CanHandle(name string) bool {
+	return name == mb.GetImplementationName()
+}
+
+func (mb *Receiver) GetImplementationName() string {
+	return "messagebus"
+}
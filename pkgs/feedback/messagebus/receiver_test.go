@@ -0,0 +1,166 @@
+package messagebus_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/messagebus"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func startNatsServer(t *testing.T) *server.Server {
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+	assert.Nil(t, err)
+	go srv.Start()
+	assert.True(t, srv.ReadyForConnections(5*time.Second))
+	return srv
+}
+
+func createPipeline(status contract.Status) contract.PipelineInfo {
+	globalCfg := config.NewData("global", map[string]string{}, &fake.NullValidator{}, logging.CreateLogger(true))
+	scm, _ := contract.NewSCMContext("https://github.com/kube-cicd/bakery")
+	scm.Commit = "76ea7c7"
+	return *contract.NewPipelineInfo(
+		scm,
+		"team-1",
+		"bread-pipeline",
+		"bread-pipeline-abc",
+		time.Now(),
+		[]contract.PipelineStage{{Name: "bake", Status: status}},
+		labels.Set{},
+		labels.Set{},
+		&globalCfg,
+	)
+}
+
+func createReceiver(cfg map[string]string) *messagebus.Receiver {
+	logger := logging.CreateLogger(true)
+	receiver := &messagebus.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.ConfigurationProvider{Contextual: config.NewData("messagebus", cfg, &fake.NullValidator{}, logger)},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+	})
+	return receiver
+}
+
+func TestReceiver_PublishesToJetStreamWithDeduplication(t *testing.T) {
+	srv := startNatsServer(t)
+	defer srv.Shutdown()
+
+	conn, _ := nats.Connect(srv.ClientURL())
+	defer conn.Close()
+	js, _ := jetstream.New(conn)
+	stream, err := js.CreateStream(context.TODO(), jetstream.StreamConfig{Name: "PIPELINES", Subjects: []string{"pipelines.>"}})
+	assert.Nil(t, err)
+
+	receiver := createReceiver(map[string]string{"servers": srv.ClientURL()})
+	defer receiver.Close()
+	log := logging.CreateLogger(true)
+
+	running := createPipeline(contract.PipelineRunning)
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), running, log))
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), running, log)) // redelivery of the same state
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineSucceeded), log))
+
+	info, _ := stream.Info(context.TODO())
+	assert.Equal(t, uint64(2), info.State.Msgs)
+
+	stored, err := stream.GetMsg(context.TODO(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "pipelines.team-1.bakery", stored.Subject)
+	assert.Equal(t, messagebus.CreateIdempotencyKey("progress", running), stored.Header.Get(messagebus.IdempotencyKeyHeader))
+
+	var msg messagebus.Message
+	assert.Nil(t, json.Unmarshal(stored.Data, &msg))
+	assert.Equal(t, "progress", msg.Event)
+	assert.Equal(t, "team-1/bread-pipeline/bread-pipeline-abc", msg.Id)
+	assert.Equal(t, "76ea7c7", msg.Scm.Commit)
+	assert.Equal(t, stored.Header.Get(messagebus.IdempotencyKeyHeader), msg.IdempotencyKey)
+}
+
+func TestReceiver_PublishesToCoreNatsWithTemplatedSubject(t *testing.T) {
+	srv := startNatsServer(t)
+	defer srv.Shutdown()
+
+	conn, _ := nats.Connect(srv.ClientURL())
+	defer conn.Close()
+	sub, _ := conn.SubscribeSync("ci.>")
+	_ = conn.Flush()
+
+	receiver := createReceiver(map[string]string{
+		"servers":   srv.ClientURL(),
+		"jetstream": "false",
+		"subject":   "ci.{{ .pipeline.GetSCMContext.OrganizationName }}.{{ .event }}",
+		"events":    "finished",
+	})
+	defer receiver.Close()
+	log := logging.CreateLogger(true)
+
+	assert.Nil(t, receiver.WhenStarted(context.TODO(), createPipeline(contract.PipelineRunning), log)) // not enabled
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineFailed), log))
+
+	received, err := sub.NextMsg(time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "ci.kube-cicd.finished", received.Subject)
+	assert.NotEmpty(t, received.Header.Get(messagebus.IdempotencyKeyHeader))
+
+	_, err = sub.NextMsg(100 * time.Millisecond)
+	assert.Equal(t, nats.ErrTimeout, err)
+}
+
+func TestReceiver_FailsWhenNoStreamAcknowledges(t *testing.T) {
+	srv := startNatsServer(t)
+	defer srv.Shutdown()
+
+	receiver := createReceiver(map[string]string{"servers": srv.ClientURL(), "timeout": "500ms"})
+	defer receiver.Close()
+	err := receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineSucceeded), logging.CreateLogger(true))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot publish 'finished' event")
+}
+
+func TestReceiver_ReplacesConnectionWhenSettingsChange(t *testing.T) {
+	srv := startNatsServer(t)
+	defer srv.Shutdown()
+
+	logger := logging.CreateLogger(true)
+	provider := &fake.ConfigurationProvider{Contextual: config.NewData("messagebus", map[string]string{
+		"servers": srv.ClientURL(), "jetstream": "false", "timeout": "5s",
+	}, &fake.NullValidator{}, logger)}
+	receiver := &messagebus.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{Config: provider, Log: logger, ConfigSchema: &fake.NullValidator{}})
+
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineSucceeded), logger))
+	assert.Eventually(t, func() bool { return srv.NumClients() == 1 }, time.Second, 10*time.Millisecond)
+
+	// e.g. a rotated password, the previous connection to the same servers is closed
+	provider.Contextual = config.NewData("messagebus", map[string]string{
+		"servers": srv.ClientURL(), "jetstream": "false", "timeout": "6s",
+	}, &fake.NullValidator{}, logger)
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineSucceeded), logger))
+	assert.Eventually(t, func() bool { return srv.NumClients() == 1 }, time.Second, 10*time.Millisecond)
+
+	receiver.Close()
+	assert.Eventually(t, func() bool { return srv.NumClients() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestCreateIdempotencyKey_DependsOnEventAndState(t *testing.T) {
+	running := createPipeline(contract.PipelineRunning)
+
+	assert.Equal(t, messagebus.CreateIdempotencyKey("progress", running), messagebus.CreateIdempotencyKey("progress", createPipeline(contract.PipelineRunning)))
+	assert.NotEqual(t, messagebus.CreateIdempotencyKey("progress", running), messagebus.CreateIdempotencyKey("started", running))
+	assert.NotEqual(t, messagebus.CreateIdempotencyKey("progress", running), messagebus.CreateIdempotencyKey("progress", createPipeline(contract.PipelineFailed)))
+}
@@ -0,0 +1,235 @@
+package msteams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/webhook"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
+	"github.com/pkg/errors"
+)
+
+// defaultCard is an Adaptive Card with a stage table, status colour, dashboard button and collapsible logs
+const defaultCard = `{
+	"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+	"type": "AdaptiveCard",
+	"version": "1.5",
+	"msteams": {"width": "Full"},
+	"body": [
+		{
+			"type": "TextBlock",
+			"size": "Large",
+			"weight": "Bolder",
+			"wrap": true,
+			"color": {{ .statusColor | toJson }},
+			"text": {{ printf "Pipeline %s %s" .pipeline.GetInstanceName .pipeline.GetStatus.AsHumanReadableDescription | toJson }}
+		},
+		{
+			"type": "FactSet",
+			"facts": [
+				{"title": "Namespace", "value": {{ .pipeline.GetNamespace | toJson }}}
+				{{- if .pipeline.GetSCMContext.RepoHttpsUrl }},
+				{"title": "Repository", "value": {{ .pipeline.GetSCMContext.RepoHttpsUrl | toJson }}}
+				{{- end }}
+				{{- if .pipeline.GetSCMContext.Commit }},
+				{"title": "Commit", "value": {{ .pipeline.GetSCMContext.Commit | toJson }}}
+				{{- end }}
+			]
+		},
+		{
+			"type": "Table",
+			"firstRowAsHeader": true,
+			"columns": [{"width": 2}, {"width": 1}],
+			"rows": [
+				{"type": "TableRow", "style": "emphasis", "cells": [
+					{"type": "TableCell", "items": [{"type": "TextBlock", "weight": "Bolder", "text": "Stage"}]},
+					{"type": "TableCell", "items": [{"type": "TextBlock", "weight": "Bolder", "text": "Status"}]}
+				]}
+				{{- range $stage := .pipeline.GetStages }},
+				{"type": "TableRow", "cells": [
+					{"type": "TableCell", "items": [{"type": "TextBlock", "wrap": true, "text": {{ $stage.Name | toJson }}}]},
+					{"type": "TableCell", "items": [{"type": "TextBlock", "text": {{ $stage.Status | toJson }}}]}
+				]}
+				{{- end }}
+			]
+		}
+		{{- if .logs }},
+		{
+			"type": "Container",
+			"id": "logs",
+			"isVisible": false,
+			"items": [{"type": "TextBlock", "fontType": "Monospace", "wrap": true, "text": {{ .logs | toJson }}}]
+		}
+		{{- end }}
+	],
+	"actions": [
+		{{- if .logs }}
+		{"type": "Action.ToggleVisibility", "title": "Show logs", "targetElements": ["logs"]}
+		{{- if .pipeline.GetDashboardUrl }},{{ end }}
+		{{- end }}
+		{{- if .pipeline.GetDashboardUrl }}
+		{"type": "Action.OpenUrl", "title": "Open in dashboard", "url": {{ .pipeline.GetDashboardUrl | toJson }}}
+		{{- end }}
+	]
+}`
+
+// message is an envelope accepted by both Incoming Webhooks and Workflows ("When a Teams webhook request is received")
+type message struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string          `json:"contentType"`
+	ContentUrl  *string         `json:"contentUrl"`
+	Content     json.RawMessage `json:"content"`
+}
+
+type Receiver struct {
+	sc *wiring.ServiceContext
+}
+
+func (mt *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing MS Teams Receiver")
+	mt.sc = sc
+
+	// register configuration options
+	mt.sc.ConfigSchema.Add(config.Schema{
+		Name: "msteams",
+		AllowedFields: []string{
+			"webhook-url",
+			"webhook-url-secret-name",
+			"webhook-url-secret-key",
+			"card",
+			"events",
+			"logs-max-length",
+			"timeout",
+		},
+	})
+	return nil
+}
+
+func (mt *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return mt.post(ctx, "created", pipeline, log)
+}
+
+func (mt *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return mt.post(ctx, "started", pipeline, log)
+}
+
+// UpdateProgress is not supported, as cards posted through a webhook cannot be edited
+func (mt *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+func (mt *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return mt.post(ctx, "finished", pipeline, log)
+}
+
+// post renders an Adaptive Card and sends it to the Incoming Webhook or Workflows URL
+func (mt *Receiver) post(ctx context.Context, event string, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := mt.sc.Config.FetchContextual("msteams", pipeline.GetNamespace(), pipeline)
+	if !cfg.HasKey("webhook-url") && !cfg.HasKey("webhook-url-secret-name") {
+		log.Debugf("Skipping MS Teams, 'msteams.webhook-url' is not configured for '%s'", pipeline.GetId())
+		return nil
+	}
+	if !webhook.IsEventEnabled(cfg.GetOrDefault("events", "finished"), event) {
+		return nil
+	}
+
+	// the URL contains a signature, so it is considered a secret
+	url, err := mt.sc.Config.FetchFromFieldOrSecret(ctx, &cfg, pipeline.GetNamespace(), "webhook-url", "webhook-url-secret-key", "webhook-url-secret-name")
+	if err != nil {
+		return errors.Wrap(err, "cannot fetch MS Teams webhook url neither from 'msteams.webhook-url' neither from a `kind: Secret` referenced in 'msteams.webhook-url-secret-name'")
+	}
+	card, err := mt.render(cfg, event, pipeline)
+	if err != nil {
+		return err
+	}
+	timeout, err := time.ParseDuration(cfg.GetOrDefault("timeout", "10s"))
+	if err != nil {
+		return errors.Wrap(err, "invalid 'msteams.timeout'")
+	}
+
+	body, _ := json.Marshal(message{
+		Type:        "message",
+		Attachments: []attachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: card}},
+	})
+	if err := send(ctx, &http.Client{Timeout: timeout}, url, body); err != nil {
+		return errors.Wrap(err, "cannot post an Adaptive Card to MS Teams")
+	}
+	return nil
+}
+
+// render creates an Adaptive Card from a template. Logs are fetched only for the "finished" event
+func (mt *Receiver) render(cfg config.Data, event string, pipeline contract.PipelineInfo) (json.RawMessage, error) {
+	logs := ""
+	if event == "finished" {
+		logsMaxLength, _ := strconv.Atoi(cfg.GetOrDefault("logs-max-length", "10000"))
+		logs = templating.Tail(pipeline.GetLogs(), logsMaxLength)
+	}
+	content, err := templating.TemplateChatMessage(cfg.GetOrDefault("card", defaultCard), "msteams-card", pipeline, map[string]interface{}{
+		"event":       event,
+		"statusColor": statusColor(pipeline.GetStatus()),
+		"logs":        logs,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create an Adaptive Card from template")
+	}
+	if !json.Valid([]byte(content)) {
+		return nil, errors.New("msteams-card template did not produce a valid JSON. Please check your template")
+	}
+	return json.RawMessage(content), nil
+}
+
+func (mt *Receiver) CanHandle(name string) bool {
+	return name == mt.GetImplementationName()
+}
+
+func (mt *Receiver) GetImplementationName() string {
+	return "msteams"
+}
+
+func send(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "cannot create HTTP request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "HTTP request failed")
+	}
+	defer response.Body.Close()
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.New(fmt.Sprintf("MS Teams responded with HTTP %v: %s", response.StatusCode, string(responseBody)))
+	}
+	return nil
+}
+
+// statusColor maps a status into Adaptive Card TextBlock color
+func statusColor(status contract.Status) string {
+	switch {
+	case status.IsSucceeded():
+		return "Good"
+	case status.IsErroredOrFailed():
+		return "Attention"
+	case status.IsCancelled() || status.IsSkipped():
+		return "Warning"
+	default:
+		return "Accent"
+	}
+}
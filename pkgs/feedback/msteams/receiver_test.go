@@ -0,0 +1,117 @@
+package msteams_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/msteams"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func createPipeline(dashboardUrl string) contract.PipelineInfo {
+	globalCfg := config.NewData("global", map[string]string{}, &fake.NullValidator{}, logging.CreateLogger(true))
+	return *contract.NewPipelineInfo(
+		contract.JobContext{Commit: "76ea7c7", RepoHttpsUrl: "https://github.com/kube-cicd/pipelines-feedback-core"},
+		"team-1",
+		"bread-pipeline",
+		"bread-pipeline-abc",
+		time.Now(),
+		[]contract.PipelineStage{
+			{Name: "knead", Status: contract.PipelineSucceeded},
+			{Name: "bake", Status: contract.PipelineFailed},
+		},
+		labels.Set{},
+		labels.Set{},
+		&globalCfg,
+		contract.PipelineInfoWithUrl(dashboardUrl),
+		contract.PipelineInfoWithLogsCollector(func() string {
+			return "The oven is \"too hot\""
+		}),
+	)
+}
+
+func createReceiver(cfg map[string]string) *msteams.Receiver {
+	logger := logging.CreateLogger(true)
+	receiver := &msteams.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.FieldConfigurationProvider{ConfigurationProvider: fake.ConfigurationProvider{Contextual: config.NewData("msteams", cfg, &fake.NullValidator{}, logger)}},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+	})
+	return receiver
+}
+
+type adaptiveCardMessage struct {
+	Type        string `json:"type"`
+	Attachments []struct {
+		ContentType string `json:"contentType"`
+		Content     struct {
+			Type    string                   `json:"type"`
+			Body    []map[string]interface{} `json:"body"`
+			Actions []map[string]interface{} `json:"actions"`
+		} `json:"content"`
+	} `json:"attachments"`
+}
+
+func TestReceiver_PostsAdaptiveCardWhenFinished(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{"webhook-url": server.URL})
+	err := receiver.WhenFinished(context.TODO(), createPipeline("https://dashboard.example.org/bread"), logging.CreateLogger(true))
+	assert.Nil(t, err)
+
+	msg := adaptiveCardMessage{}
+	assert.Nil(t, json.Unmarshal(received, &msg))
+	assert.Equal(t, "message", msg.Type)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", msg.Attachments[0].ContentType)
+
+	card := msg.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	assert.Equal(t, "Attention", card.Body[0]["color"])
+	assert.Equal(t, "Table", card.Body[2]["type"])
+	assert.Len(t, card.Body[2]["rows"], 3) // header + 2 stages
+	assert.Equal(t, false, card.Body[3]["isVisible"])
+	assert.Equal(t, "Action.ToggleVisibility", card.Actions[0]["type"])
+	assert.Equal(t, "https://dashboard.example.org/bread", card.Actions[1]["url"])
+	assert.Contains(t, string(received), `The oven is \"too hot\"`)
+}
+
+func TestReceiver_SkipsNotSelectedEvents(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{"webhook-url": server.URL})
+	assert.Nil(t, receiver.WhenStarted(context.TODO(), createPipeline(""), logging.CreateLogger(true)))
+	assert.Equal(t, 0, calls)
+
+	receiver = createReceiver(map[string]string{"webhook-url": server.URL, "events": "started,finished"})
+	assert.Nil(t, receiver.WhenStarted(context.TODO(), createPipeline(""), logging.CreateLogger(true)))
+	assert.Equal(t, 1, calls)
+}
+
+func TestReceiver_InvalidCardTemplate(t *testing.T) {
+	receiver := createReceiver(map[string]string{"webhook-url": "http://127.0.0.1:1", "card": `{"type": {{ .pipeline.GetNamespace }}}`})
+	err := receiver.WhenFinished(context.TODO(), createPipeline(""), logging.CreateLogger(true))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "valid JSON")
+}
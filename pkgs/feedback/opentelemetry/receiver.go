@@ -0,0 +1,305 @@
+package opentelemetry
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/webhook"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ProtocolGrpc = "grpc"
+	ProtocolHttp = "http/protobuf"
+)
+
+// Receiver exports each finished Pipeline as an OTLP trace. The Pipeline is a root span, each stage is a child span
+type Receiver struct {
+	sc *wiring.ServiceContext
+
+	// exporters are kept between reconciliations, one per endpoint
+	exporters map[exporterEndpoint]exporter
+	mutex     sync.Mutex
+}
+
+// exporterEndpoint identifies an exporter, an exporter with changed settings (e.g. headers) replaces the previous one
+type exporterEndpoint struct {
+	protocol string
+	endpoint string
+}
+
+type exporterSettings struct {
+	exporterEndpoint
+	insecure bool
+	headers  string
+	timeout  time.Duration
+}
+
+// exporter is a SpanExporter together with the settings it was created with
+type exporter struct {
+	settings exporterSettings
+	exporter sdktrace.SpanExporter
+}
+
+func (o *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing OpenTelemetry Receiver")
+	o.sc = sc
+	o.exporters = make(map[exporterEndpoint]exporter)
+
+	// register configuration options
+	o.sc.ConfigSchema.Add(config.Schema{
+		Name: "opentelemetry",
+		AllowedFields: []string{
+			"endpoint",
+			"protocol",
+			"insecure",
+			"headers",
+			"service-name",
+			"timeout",
+		},
+	})
+	return nil
+}
+
+func (o *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+func (o *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+func (o *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return nil
+}
+
+// WhenFinished builds spans from the Pipeline and its stages, then exports them synchronously, so the errors are not lost
+func (o *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := o.sc.Config.FetchContextual("opentelemetry", pipeline.GetNamespace(), pipeline)
+	if cfg.Get("endpoint") == "" {
+		log.Debugf("Skipping OpenTelemetry, 'opentelemetry.endpoint' is not configured for '%s'", pipeline.GetId())
+		return nil
+	}
+	timeout, err := time.ParseDuration(cfg.GetOrDefault("timeout", "10s"))
+	if err != nil {
+		return errors.Wrap(err, "invalid 'opentelemetry.timeout'")
+	}
+	spanExporter, err := o.getExporter(ctx, exporterSettings{
+		exporterEndpoint: exporterEndpoint{protocol: cfg.GetOrDefault("protocol", ProtocolGrpc), endpoint: cfg.Get("endpoint")},
+		insecure:         cfg.GetOrDefault("insecure", "false") == "true",
+		headers:          cfg.Get("headers"),
+		timeout:          timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	collector := &spanCollector{}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(collector),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.GetOrDefault("service-name", "pipelines-feedback")))),
+	)
+	defer provider.Shutdown(context.Background())
+
+	RecordPipeline(ctx, provider.Tracer("github.com/kube-cicd/pipelines-feedback-core"), pipeline, time.Now())
+
+	exportCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := spanExporter.ExportSpans(exportCtx, collector.spans); err != nil {
+		return errors.Wrap(err, "cannot export Pipeline trace")
+	}
+	log.Debugf("Exported %v spans for '%s'", len(collector.spans), pipeline.GetId())
+	return nil
+}
+
+// RecordPipeline creates a root span for the Pipeline and a child span per stage. Unknown stage times are replaced
+// with the Pipeline times, unknown Pipeline end time is replaced with `now`
+func RecordPipeline(ctx context.Context, tracer trace.Tracer, pipeline contract.PipelineInfo, now time.Time) {
+	start, end := pipelineTimeRange(pipeline, now)
+	scmCtx := pipeline.GetSCMContext()
+
+	attributes := []attribute.KeyValue{
+		attribute.String("cicd.pipeline.name", pipeline.GetName()),
+		attribute.String("cicd.pipeline.run.id", pipeline.GetId()),
+		attribute.String("cicd.pipeline.result", string(pipeline.GetStatus())),
+		attribute.String("k8s.namespace.name", pipeline.GetNamespace()),
+	}
+	for key, value := range map[string]string{
+		"vcs.repository.url.full": scmCtx.RepoHttpsUrl,
+		"vcs.ref.head.revision":   scmCtx.Commit,
+		"vcs.ref.head.name":       scmCtx.SourceBranch,
+		"vcs.ref.base.name":       scmCtx.TargetBranch,
+		"vcs.change.id":           scmCtx.PrId,
+		"vcs.ref.tag":             scmCtx.Tag,
+		"cicd.pipeline.run.url":   pipeline.GetDashboardUrl(),
+	} {
+		if value != "" {
+			attributes = append(attributes, attribute.String(key, value))
+		}
+	}
+
+	ctx, root := tracer.Start(ctx, pipeline.GetName(), trace.WithTimestamp(start), trace.WithAttributes(attributes...))
+	for _, stage := range pipeline.GetStages() {
+		stageStart, stageEnd := stage.StartedAt, stage.FinishedAt
+		if stageStart.IsZero() {
+			stageStart = start
+		}
+		if stageEnd.IsZero() {
+			stageEnd = end
+		}
+		_, span := tracer.Start(ctx, stage.Name, trace.WithTimestamp(stageStart), trace.WithAttributes(
+			attribute.String("cicd.pipeline.task.name", stage.Name),
+			attribute.String("cicd.pipeline.task.run.result", string(stage.Status)),
+		))
+		setStatus(span, stage.Status)
+		span.End(trace.WithTimestamp(stageEnd))
+	}
+	setStatus(root, pipeline.GetStatus())
+	root.End(trace.WithTimestamp(end))
+}
+
+// pipelineTimeRange finds the earliest start and the latest end of the Pipeline and its stages
+func pipelineTimeRange(pipeline contract.PipelineInfo, now time.Time) (time.Time, time.Time) {
+	start, end := pipeline.GetDateStarted(), time.Time{}
+	for _, stage := range pipeline.GetStages() {
+		if !stage.StartedAt.IsZero() && (start.IsZero() || stage.StartedAt.Before(start)) {
+			start = stage.StartedAt
+		}
+		if stage.FinishedAt.After(end) {
+			end = stage.FinishedAt
+		}
+	}
+	if end.IsZero() {
+		end = now
+	}
+	if start.IsZero() || start.After(end) {
+		start = end
+	}
+	return start, end
+}
+
+func setStatus(span trace.Span, status contract.Status) {
+	if status.IsErroredOrFailed() {
+		span.SetStatus(codes.Error, status.AsHumanReadableDescription())
+	} else if status.IsSucceeded() {
+		span.SetStatus(codes.Ok, "")
+	}
+}
+
+// getExporter returns an already created exporter, or creates one. An exporter for the same endpoint created
+// with other settings is shut down first
+func (o *Receiver) getExporter(ctx context.Context, settings exporterSettings) (sdktrace.SpanExporter, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if existing, exists := o.exporters[settings.exporterEndpoint]; exists {
+		if existing.settings == settings {
+			return existing.exporter, nil
+		}
+		_ = existing.exporter.Shutdown(ctx)
+		delete(o.exporters, settings.exporterEndpoint)
+	}
+	created, err := createExporter(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+	o.exporters[settings.exporterEndpoint] = exporter{settings: settings, exporter: created}
+	return created, nil
+}
+
+// Close shuts down all exporters
+func (o *Receiver) Close() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for key, existing := range o.exporters {
+		_ = existing.exporter.Shutdown(context.Background())
+		delete(o.exporters, key)
+	}
+}
+
+func createExporter(ctx context.Context, settings exporterSettings) (sdktrace.SpanExporter, error) {
+	endpoint := settings.endpoint
+	parsedHeaders, err := webhook.ParseHeaders(settings.headers)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid 'opentelemetry.headers'")
+	}
+	headers := make(map[string]string, len(parsedHeaders))
+	for name, values := range parsedHeaders {
+		headers[name] = strings.Join(values, ",")
+	}
+
+	switch settings.protocol {
+	case ProtocolGrpc:
+		options := []otlptracegrpc.Option{otlptracegrpc.WithTimeout(settings.timeout), otlptracegrpc.WithHeaders(headers)}
+		if strings.Contains(endpoint, "://") {
+			options = append(options, otlptracegrpc.WithEndpointURL(endpoint))
+		} else {
+			options = append(options, otlptracegrpc.WithEndpoint(endpoint))
+		}
+		if settings.insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, options...)
+		return exporter, errors.Wrap(err, "cannot create OTLP gRPC exporter")
+
+	case ProtocolHttp:
+		options := []otlptracehttp.Option{otlptracehttp.WithTimeout(settings.timeout), otlptracehttp.WithHeaders(headers)}
+		if strings.Contains(endpoint, "://") {
+			// "https://collector:4318" is completed to "https://collector:4318/v1/traces"
+			if parsed, err := url.Parse(endpoint); err == nil && strings.Trim(parsed.Path, "/") == "" {
+				endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+			}
+			options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+		} else {
+			options = append(options, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if settings.insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		return exporter, errors.Wrap(err, "cannot create OTLP HTTP exporter")
+	}
+	return nil, errors.New("invalid 'opentelemetry.protocol', expected 'grpc' or 'http/protobuf'")
+}
+
+// spanCollector is keeping ended spans in memory, so they can be exported at once with error handling
+type spanCollector struct {
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (sc *spanCollector) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {}
+
+func (sc *spanCollector) OnEnd(s sdktrace.ReadOnlySpan) {
+	sc.spans = append(sc.spans, s)
+}
+
+func (sc *spanCollector) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (sc *spanCollector) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+func (o *Receiver) CanHandle(name string) bool {
+	return name == o.GetImplementationName()
+}
+
+func (o *Receiver) GetImplementationName() string {
+	return "opentelemetry"
+}
@@ -0,0 +1,173 @@
+package opentelemetry_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/opentelemetry"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/stretchr/testify/assert"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var pipelineStart = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func createPipeline() contract.PipelineInfo {
+	globalCfg := config.NewData("global", map[string]string{}, &fake.NullValidator{}, logging.CreateLogger(true))
+	return *contract.NewPipelineInfo(
+		contract.JobContext{Commit: "76ea7c7", RepoHttpsUrl: "https://github.com/kube-cicd/pipelines-feedback-core", PrId: "161"},
+		"team-1",
+		"bread-pipeline",
+		"bread-pipeline-abc",
+		pipelineStart,
+		[]contract.PipelineStage{
+			{Name: "knead", Status: contract.PipelineSucceeded, StartedAt: pipelineStart, FinishedAt: pipelineStart.Add(time.Minute)},
+			{Name: "bake", Status: contract.PipelineFailed, StartedAt: pipelineStart.Add(time.Minute), FinishedAt: pipelineStart.Add(5 * time.Minute)},
+		},
+		labels.Set{},
+		labels.Set{},
+		&globalCfg,
+	)
+}
+
+func createReceiver(cfg map[string]string) *opentelemetry.Receiver {
+	logger := logging.CreateLogger(true)
+	receiver := &opentelemetry.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.ConfigurationProvider{Contextual: config.NewData("opentelemetry", cfg, &fake.NullValidator{}, logger)},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+	})
+	return receiver
+}
+
+func findAttribute(span *tracepb.Span, key string) string {
+	for _, attr := range span.GetAttributes() {
+		if attr.GetKey() == key {
+			return attr.GetValue().GetStringValue()
+		}
+	}
+	return ""
+}
+
+func TestReceiver_ExportsPipelineTraceOverHttp(t *testing.T) {
+	var received collectortrace.ExportTraceServiceRequest
+	var path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		assert.Nil(t, proto.Unmarshal(body, &received))
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{
+		"endpoint":     server.URL,
+		"protocol":     "http/protobuf",
+		"headers":      "Authorization: Bearer croissant",
+		"service-name": "bakery-ci",
+	})
+	err := receiver.WhenFinished(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/v1/traces", path)
+	assert.Equal(t, "Bearer croissant", authorization)
+	assert.Len(t, received.ResourceSpans, 1)
+	assert.Equal(t, "bakery-ci", received.ResourceSpans[0].GetResource().GetAttributes()[0].GetValue().GetStringValue())
+
+	spans := make(map[string]*tracepb.Span)
+	for _, scopeSpans := range received.ResourceSpans[0].ScopeSpans {
+		for _, span := range scopeSpans.Spans {
+			spans[span.Name] = span
+		}
+	}
+	assert.Len(t, spans, 3)
+
+	root := spans["team-1/bread-pipeline"]
+	assert.Empty(t, root.ParentSpanId)
+	assert.Equal(t, uint64(pipelineStart.UnixNano()), root.StartTimeUnixNano)
+	assert.Equal(t, uint64(pipelineStart.Add(5*time.Minute).UnixNano()), root.EndTimeUnixNano)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, root.Status.Code)
+	assert.Equal(t, "team-1", findAttribute(root, "k8s.namespace.name"))
+	assert.Equal(t, "76ea7c7", findAttribute(root, "vcs.ref.head.revision"))
+	assert.Equal(t, "161", findAttribute(root, "vcs.change.id"))
+
+	knead := spans["knead"]
+	assert.Equal(t, root.SpanId, knead.ParentSpanId)
+	assert.Equal(t, root.TraceId, knead.TraceId)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_OK, knead.Status.Code)
+	assert.Equal(t, uint64(pipelineStart.Add(time.Minute).UnixNano()), knead.EndTimeUnixNano)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, spans["bake"].Status.Code)
+}
+
+func TestReceiver_ReturnsExportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{"endpoint": server.URL, "protocol": "http/protobuf"})
+	err := receiver.WhenFinished(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot export Pipeline trace")
+}
+
+func TestReceiver_ReusesExporterBetweenPipelines(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{"endpoint": server.URL, "protocol": "http/protobuf"})
+	defer receiver.Close()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(), logging.CreateLogger(true)))
+	}
+
+	assert.Equal(t, int32(1), connections.Load(), "the exporter keeps its connection between Pipelines")
+}
+
+func TestReceiver_RejectsInvalidHeaders(t *testing.T) {
+	receiver := createReceiver(map[string]string{"endpoint": "localhost:4317", "headers": "Authorization=Bearer croissant"})
+	err := receiver.WhenFinished(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid 'opentelemetry.headers'")
+}
+
+func TestReceiver_SkipsWhenEndpointNotConfigured(t *testing.T) {
+	receiver := createReceiver(map[string]string{})
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(), logging.CreateLogger(true)))
+}
+
+func TestReceiver_RejectsUnknownProtocol(t *testing.T) {
+	receiver := createReceiver(map[string]string{"endpoint": "localhost:4317", "protocol": "carrier-pigeon"})
+	err := receiver.WhenFinished(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid 'opentelemetry.protocol'")
+}
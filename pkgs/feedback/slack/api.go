@@ -0,0 +1,86 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const DefaultApiUrl = "https://slack.com/api"
+
+// apiClient is a minimal Slack Web API client covering chat.postMessage and chat.update
+type apiClient struct {
+	baseUrl string
+	token   string
+	client  *http.Client
+}
+
+type message struct {
+	Channel  string          `json:"channel"`
+	Ts       string          `json:"ts,omitempty"`
+	ThreadTs string          `json:"thread_ts,omitempty"`
+	Text     string          `json:"text"`
+	Blocks   json.RawMessage `json:"blocks,omitempty"`
+}
+
+type apiResponse struct {
+	Ok      bool   `json:"ok"`
+	Error   string `json:"error"`
+	Channel string `json:"channel"`
+	Ts      string `json:"ts"`
+}
+
+func newApiClient(baseUrl string, token string) *apiClient {
+	return &apiClient{baseUrl: baseUrl, token: token, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// postMessage posts a new message or a threaded reply when msg.ThreadTs is set. Returns the channel ID and the message timestamp
+func (c *apiClient) postMessage(ctx context.Context, msg message) (string, string, error) {
+	response, err := c.call(ctx, "chat.postMessage", msg)
+	if err != nil {
+		return "", "", err
+	}
+	return response.Channel, response.Ts, nil
+}
+
+// updateMessage edits an existing message identified by channel ID and timestamp
+func (c *apiClient) updateMessage(ctx context.Context, msg message) error {
+	_, err := c.call(ctx, "chat.update", msg)
+	return err
+}
+
+func (c *apiClient) call(ctx context.Context, method string, msg message) (apiResponse, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return apiResponse{}, errors.Wrap(err, "cannot encode Slack message")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseUrl+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return apiResponse{}, errors.Wrap(err, "cannot create HTTP request")
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	httpResponse, err := c.client.Do(req)
+	if err != nil {
+		return apiResponse{}, errors.Wrapf(err, "cannot call Slack API '%s'", method)
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		return apiResponse{}, errors.New(fmt.Sprintf("Slack API '%s' responded with HTTP %v", method, httpResponse.StatusCode))
+	}
+
+	response := apiResponse{}
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return apiResponse{}, errors.Wrapf(err, "cannot decode Slack API '%s' response", method)
+	}
+	if !response.Ok {
+		return response, errors.New(fmt.Sprintf("Slack API '%s' returned an error: %s", method, response.Error))
+	}
+	return response, nil
+}
@@ -0,0 +1,248 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
+	"github.com/pkg/errors"
+)
+
+// defaultMessage is a Block Kit "blocks" array
+const defaultMessage = `[
+	{
+		"type": "section",
+		"text": {"type": "mrkdwn", "text": {{ printf "%s Pipeline *%s* %s" .statusEmoji .pipeline.GetInstanceName .pipeline.GetStatus.AsHumanReadableDescription | toJson }}}
+	},
+	{
+		"type": "section",
+		"text": {"type": "mrkdwn", "text": {{ .stages | toJson }}}
+	},
+	{
+		"type": "context",
+		"elements": [
+			{"type": "mrkdwn", "text": {{ printf "Namespace: %s" .pipeline.GetNamespace | toJson }}}
+			{{- if .pipeline.GetSCMContext.Commit }},
+			{"type": "mrkdwn", "text": {{ printf "Commit: %s" .pipeline.GetSCMContext.Commit | toJson }}}
+			{{- end }}
+			{{- if .pipeline.GetDashboardUrl }},
+			{"type": "mrkdwn", "text": {{ printf "<%s|Open in dashboard>" .pipeline.GetDashboardUrl | toJson }}}
+			{{- end }}
+		]
+	}
+]`
+
+// defaultFinishedMessage is a Block Kit "blocks" array posted as a threaded reply
+const defaultFinishedMessage = `[
+	{
+		"type": "section",
+		"text": {"type": "mrkdwn", "text": {{ printf "%s The Pipeline finished with status *%s*" .statusEmoji .pipeline.GetStatus | toJson }}}
+	}
+	{{- if .logs }},
+	{
+		"type": "section",
+		"text": {"type": "mrkdwn", "text": {{ printf "*Build logs:*\n~~~%s~~~" .logs | toJson }}}
+	}
+	{{- end }}
+]`
+
+type Receiver struct {
+	sc *wiring.ServiceContext
+}
+
+func (s *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing Slack Receiver")
+	s.sc = sc
+
+	// register configuration options
+	s.sc.ConfigSchema.Add(config.Schema{
+		Name: "slack",
+		AllowedFields: []string{
+			"token",
+			"token-secret-name",
+			"token-secret-key",
+			"channel",
+			"message",
+			"finished-message",
+			"logs-max-length",
+			"api-url",
+		},
+	})
+	return nil
+}
+
+func (s *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return s.UpdateProgress(ctx, pipeline, log)
+}
+
+func (s *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return s.UpdateProgress(ctx, pipeline, log)
+}
+
+// UpdateProgress posts a single message per Pipeline, then keeps editing it in place
+func (s *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := s.sc.Config.FetchContextual("slack", pipeline.GetNamespace(), pipeline)
+	if cfg.Get("channel") == "" {
+		log.Debugf("Skipping Slack, 'slack.channel' is not configured for '%s'", pipeline.GetId())
+		return nil
+	}
+	client, err := s.createClient(ctx, cfg, pipeline)
+	if err != nil {
+		return err
+	}
+	_, err = s.upsertStatusMessage(ctx, cfg, client, pipeline, log)
+	return err
+}
+
+// WhenFinished updates the status message for the last time, then replies in its thread with a summary and logs excerpt
+func (s *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := s.sc.Config.FetchContextual("slack", pipeline.GetNamespace(), pipeline)
+	if cfg.Get("channel") == "" {
+		return nil
+	}
+	client, err := s.createClient(ctx, cfg, pipeline)
+	if err != nil {
+		return err
+	}
+	reference, err := s.upsertStatusMessage(ctx, cfg, client, pipeline, log)
+	if err != nil {
+		return err
+	}
+	if s.sc.Store.WasEventAlreadySent(pipeline, "slack/finished-reply") {
+		log.Debugf("Skipping Slack reply, already posted for '%s'", pipeline.GetId())
+		return nil
+	}
+
+	blocks, err := s.render(cfg.GetOrDefault("finished-message", defaultFinishedMessage), "slack-finished-message", cfg, pipeline, true)
+	if err != nil {
+		return err
+	}
+	channelId, ts := splitReference(reference)
+	if _, _, err := client.postMessage(ctx, message{
+		Channel:  channelId,
+		ThreadTs: ts,
+		Text:     fmt.Sprintf("The Pipeline '%s' finished with status '%s'", pipeline.GetInstanceName(), pipeline.GetStatus()),
+		Blocks:   blocks,
+	}); err != nil {
+		return errors.Wrap(err, "cannot post a threaded reply to Slack")
+	}
+	return s.sc.Store.RecordEventFiring(pipeline, "slack/finished-reply")
+}
+
+// upsertStatusMessage posts or edits the status message, returns its "channelId/ts" reference
+func (s *Receiver) upsertStatusMessage(ctx context.Context, cfg config.Data, client *apiClient, pipeline contract.PipelineInfo,
+	log *logging.InternalLogger) (string, error) {
+
+	blocks, err := s.render(cfg.GetOrDefault("message", defaultMessage), "slack-message", cfg, pipeline, false)
+	if err != nil {
+		return "", err
+	}
+	text := fmt.Sprintf("Pipeline '%s' %s", pipeline.GetInstanceName(), pipeline.GetStatus().AsHumanReadableDescription())
+	reference := s.sc.Store.GetMessageReference(pipeline, "slack")
+
+	// 1. Post a new message
+	if reference == "" {
+		channel, tplErr := templating.TemplateChatMessage(cfg.Get("channel"), "slack-channel", pipeline, map[string]interface{}{})
+		if tplErr != nil {
+			return "", tplErr
+		}
+		channelId, ts, postErr := client.postMessage(ctx, message{Channel: strings.TrimSpace(channel), Text: text, Blocks: blocks})
+		if postErr != nil {
+			return "", errors.Wrap(postErr, "cannot post a message to Slack")
+		}
+		reference = channelId + "/" + ts
+		s.sc.Store.RecordMessageReference(pipeline, "slack", reference, string(blocks))
+		return reference, nil
+	}
+
+	// 2. Edit the existing message, if anything changed
+	if s.sc.Store.IsMessageUpToDate(pipeline, "slack", string(blocks)) {
+		log.Debugf("Skipping Slack update, message already up-to-date for '%s'", pipeline.GetId())
+		return reference, nil
+	}
+	channelId, ts := splitReference(reference)
+	if updateErr := client.updateMessage(ctx, message{Channel: channelId, Ts: ts, Text: text, Blocks: blocks}); updateErr != nil {
+		return "", errors.Wrap(updateErr, "cannot update a message in Slack")
+	}
+	s.sc.Store.RecordMessageReference(pipeline, "slack", reference, string(blocks))
+	return reference, nil
+}
+
+// render creates a Block Kit "blocks" array from a template. Logs are fetched only when requested, as it is an expensive operation
+func (s *Receiver) render(templateStr string, name string, cfg config.Data, pipeline contract.PipelineInfo, withLogs bool) (json.RawMessage, error) {
+	logs := ""
+	if withLogs {
+		logsMaxLength, _ := strconv.Atoi(cfg.GetOrDefault("logs-max-length", "2500"))
+		logs = templating.Tail(pipeline.GetLogs(), logsMaxLength)
+	}
+	content, err := templating.TemplateChatMessage(strings.ReplaceAll(templateStr, "~~~", "```"), name, pipeline, map[string]interface{}{
+		"statusEmoji": statusEmoji(pipeline.GetStatus()),
+		"stages":      stagesSummary(pipeline),
+		"logs":        logs,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create a Slack message from template")
+	}
+	if !json.Valid([]byte(content)) {
+		return nil, errors.New(name + " template did not produce a valid JSON. Please check your template")
+	}
+	return json.RawMessage(content), nil
+}
+
+func (s *Receiver) createClient(ctx context.Context, cfg config.Data, pipeline contract.PipelineInfo) (*apiClient, error) {
+	// "slack.token" as plaintext, or a `kind: Secret` referenced by "slack.token-secret-name" and "slack.token-secret-key"
+	token, err := s.sc.Config.FetchFromFieldOrSecret(ctx, &cfg, pipeline.GetNamespace(), "token", "token-secret-key", "token-secret-name")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot fetch Slack token neither from 'slack.token' as plaintext neither from a `kind: Secret` referenced in 'slack.token-secret-name'")
+	}
+	if token == "" {
+		return nil, errors.New("cannot fetch Slack token neither from 'slack.token' as plaintext neither from a `kind: Secret` referenced in 'slack.token-secret-name'")
+	}
+	return newApiClient(cfg.GetOrDefault("api-url", DefaultApiUrl), token), nil
+}
+
+func (s *Receiver) CanHandle(name string) bool {
+	return name == s.GetImplementationName()
+}
+
+func (s *Receiver) GetImplementationName() string {
+	return "slack"
+}
+
+func splitReference(reference string) (string, string) {
+	channelId, ts, _ := strings.Cut(reference, "/")
+	return channelId, ts
+}
+
+func statusEmoji(status contract.Status) string {
+	switch {
+	case status.IsSucceeded():
+		return ":white_check_mark:"
+	case status.IsErroredOrFailed():
+		return ":x:"
+	case status.IsRunning():
+		return ":hourglass_flowing_sand:"
+	case status.IsCancelled() || status.IsSkipped():
+		return ":heavy_minus_sign:"
+	default:
+		return ":timer_clock:"
+	}
+}
+
+func stagesSummary(pipeline contract.PipelineInfo) string {
+	lines := make([]string, 0, len(pipeline.GetStages()))
+	for _, stage := range pipeline.GetStages() {
+		lines = append(lines, fmt.Sprintf("%s %s", statusEmoji(stage.Status), stage.Name))
+	}
+	if len(lines) == 0 {
+		return "_No stages_"
+	}
+	return strings.Join(lines, "\n")
+}
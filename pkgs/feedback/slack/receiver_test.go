@@ -0,0 +1,150 @@
+package slack_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/slack"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type apiCall struct {
+	method        string
+	authorization string
+	body          map[string]interface{}
+}
+
+// fakeSlack is a local HTTP server pretending to be the Slack Web API
+type fakeSlack struct {
+	sync.Mutex
+	server *httptest.Server
+	calls  []apiCall
+}
+
+func newFakeSlack() *fakeSlack {
+	f := &fakeSlack{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		f.Lock()
+		defer f.Unlock()
+		f.calls = append(f.calls, apiCall{method: strings.TrimPrefix(r.URL.Path, "/"), authorization: r.Header.Get("Authorization"), body: body})
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "C0BREAD", "ts": "1503435956.000247"}`))
+	}))
+	return f
+}
+
+func createPipeline(status contract.Status) contract.PipelineInfo {
+	return createPipelineWithLogs(status, "The bread is ready")
+}
+
+func createPipelineWithLogs(status contract.Status, logs string) contract.PipelineInfo {
+	globalCfg := config.NewData("global", map[string]string{}, &fake.NullValidator{}, logging.CreateLogger(true))
+	return *contract.NewPipelineInfo(
+		contract.JobContext{Commit: "76ea7c7"},
+		"team-1",
+		"bread-pipeline",
+		"bread-pipeline-abc",
+		time.Now(),
+		[]contract.PipelineStage{{Name: "bake", Status: status}},
+		labels.Set{},
+		labels.Set{},
+		&globalCfg,
+		contract.PipelineInfoWithLogsCollector(func() string {
+			return logs
+		}),
+	)
+}
+
+func createReceiver(apiUrl string) *slack.Receiver {
+	return createReceiverWithConfig(apiUrl, map[string]string{})
+}
+
+func createReceiverWithConfig(apiUrl string, cfg map[string]string) *slack.Receiver {
+	logger := logging.CreateLogger(true)
+	cfg["api-url"] = apiUrl
+	cfg["token"] = "xoxb-bakery"
+	cfg["channel"] = "#{{ .pipeline.GetNamespace }}-builds"
+	receiver := &slack.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.FieldConfigurationProvider{ConfigurationProvider: fake.ConfigurationProvider{Contextual: config.NewData("slack", cfg, &fake.NullValidator{}, logger)}},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+		Store:        &store.Operator{Store: store.NewMemory()},
+	})
+	return receiver
+}
+
+func TestReceiver_PostsOnceThenEditsAndRepliesInThread(t *testing.T) {
+	api := newFakeSlack()
+	defer api.server.Close()
+	receiver := createReceiver(api.server.URL)
+	log := logging.CreateLogger(true)
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelinePending), log))
+	assert.Nil(t, receiver.WhenCreated(context.TODO(), createPipeline(contract.PipelinePending), log)) // no changes, no call
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning), log))
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineSucceeded), log))
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(contract.PipelineSucceeded), log)) // reply only once
+
+	methods := make([]string, 0)
+	for _, call := range api.calls {
+		methods = append(methods, call.method)
+		assert.Equal(t, "Bearer xoxb-bakery", call.authorization)
+	}
+	assert.Equal(t, []string{"chat.postMessage", "chat.update", "chat.update", "chat.postMessage"}, methods)
+
+	// new message goes to the templated channel, edits and replies use the channel ID and message timestamp
+	assert.Equal(t, "#team-1-builds", api.calls[0].body["channel"])
+	assert.Equal(t, "C0BREAD", api.calls[1].body["channel"])
+	assert.Equal(t, "1503435956.000247", api.calls[1].body["ts"])
+	assert.Equal(t, "1503435956.000247", api.calls[3].body["thread_ts"])
+
+	reply, _ := json.Marshal(api.calls[3].body["blocks"])
+	assert.Contains(t, string(reply), "The bread is ready")
+	assert.Contains(t, string(reply), "succeeded")
+}
+
+func TestReceiver_TruncatesLogsOnCharacterBoundary(t *testing.T) {
+	api := newFakeSlack()
+	defer api.server.Close()
+	receiver := createReceiverWithConfig(api.server.URL, map[string]string{"logs-max-length": "5"})
+	log := logging.CreateLogger(true)
+
+	// each of "ż", "ó" and "ł" takes two bytes, a cut by bytes would start in the middle of "ó"
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipelineWithLogs(contract.PipelineSucceeded, "Chleb żółty"), log))
+
+	assert.Len(t, api.calls, 2)
+	reply, _ := json.Marshal(api.calls[1].body["blocks"])
+	assert.True(t, utf8.Valid(reply))
+	assert.Contains(t, string(reply), "```...łty```")
+	assert.NotContains(t, string(reply), "\\ufffd")
+}
+
+func TestReceiver_NotConfigured(t *testing.T) {
+	logger := logging.CreateLogger(true)
+	receiver := &slack.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.FieldConfigurationProvider{ConfigurationProvider: fake.ConfigurationProvider{Contextual: config.NewData("slack", map[string]string{}, &fake.NullValidator{}, logger)}},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+		Store:        &store.Operator{Store: store.NewMemory()},
+	})
+
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline(contract.PipelineRunning), logger))
+}
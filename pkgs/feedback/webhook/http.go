@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/pkg/errors"
+)
+
+// DefaultSignatureHeader contains "sha256=<hex encoded HMAC-SHA256 of the body>"
+const DefaultSignatureHeader = "X-Pipelines-Feedback-Signature-256"
+
+type request struct {
+	method          string
+	url             string
+	body            []byte
+	headers         http.Header
+	secret          string
+	signatureHeader string
+	successCodes    []statusCodeRange
+	timeout         time.Duration
+	retries         int
+	retryDelay      time.Duration
+	retryMaxTime    time.Duration
+}
+
+type statusCodeRange struct {
+	from int
+	to   int
+}
+
+// Sign calculates a signature of the body in the same format as sent in the signature header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// send is performing the request. Connection errors, HTTP 429 and HTTP 5xx are retried, as long as retryMaxTime allows.
+// The reconciliation worker is not held for longer, further attempts are made when the controller requeues the object
+func send(ctx context.Context, req request, log *logging.InternalLogger) error {
+	client := &http.Client{Timeout: req.timeout}
+	ctx, cancel := context.WithTimeout(ctx, req.retryMaxTime)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	var lastErr error
+
+	for attempt := 0; attempt <= req.retries; attempt++ {
+		if attempt > 0 {
+			if time.Until(deadline) <= req.retryDelay {
+				log.Debugf("No time left to retry webhook request to '%s', leaving it to the next reconciliation", req.url)
+				break
+			}
+			log.Debugf("Retrying webhook request to '%s' (attempt %v): %s", req.url, attempt, lastErr.Error())
+			select {
+			case <-ctx.Done():
+				return errors.Wrap(ctx.Err(), "cancelled while waiting for a retry")
+			case <-time.After(req.retryDelay):
+			}
+		}
+
+		retryable, err := sendOnce(ctx, client, req)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+	return lastErr
+}
+
+func sendOnce(ctx context.Context, client *http.Client, req request) (bool, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, req.url, bytes.NewReader(req.body))
+	if err != nil {
+		return false, errors.Wrap(err, "cannot create HTTP request")
+	}
+	for name, values := range req.headers {
+		for _, value := range values {
+			httpReq.Header.Add(name, value)
+		}
+	}
+	if req.secret != "" {
+		httpReq.Header.Set(req.signatureHeader, Sign(req.secret, req.body))
+	}
+
+	response, err := client.Do(httpReq)
+	if err != nil {
+		return true, errors.Wrap(err, "HTTP request failed")
+	}
+	defer response.Body.Close()
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+	if isSuccessful(req.successCodes, response.StatusCode) {
+		return false, nil
+	}
+	retryable := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+	return retryable, errors.New(fmt.Sprintf("endpoint responded with HTTP %v: %s", response.StatusCode, string(responseBody)))
+}
+
+func isSuccessful(codes []statusCodeRange, status int) bool {
+	for _, code := range codes {
+		if status >= code.from && status <= code.to {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStatusCodes parses a list of codes and ranges e.g. "200-299,304"
+func parseStatusCodes(input string) ([]statusCodeRange, error) {
+	codes := make([]statusCodeRange, 0)
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid status code '%s'", part)
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, errors.Wrapf(err, "invalid status code range '%s'", part)
+			}
+		}
+		codes = append(codes, statusCodeRange{from: from, to: to})
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("at least one status code is required")
+	}
+	return codes, nil
+}
+
+// ParseHeaders parses one "Name: value" header per line
+func ParseHeaders(input string) (http.Header, error) {
+	headers := http.Header{}
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, errors.New(fmt.Sprintf("invalid header '%s', expected 'Name: value' format", line))
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+func parsePositiveInt(input string) (int, error) {
+	value, err := strconv.Atoi(input)
+	if err != nil {
+		return 0, err
+	}
+	if value < 0 {
+		return 0, errors.New("value cannot be negative")
+	}
+	return value, nil
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+)
+
+// Payload is a default JSON document sent to the endpoint, available in templates as `.payload`
+type Payload struct {
+	Event        string          `json:"event"`
+	Id           string          `json:"id"`
+	Namespace    string          `json:"namespace"`
+	Name         string          `json:"name"`
+	Status       contract.Status `json:"status"`
+	Description  string          `json:"description"`
+	DashboardUrl string          `json:"dashboardUrl,omitempty"`
+	Stages       []PayloadStage  `json:"stages"`
+	Scm          PayloadScm      `json:"scm"`
+}
+
+type PayloadStage struct {
+	Name   string          `json:"name"`
+	Status contract.Status `json:"status"`
+}
+
+type PayloadScm struct {
+	Repository   string `json:"repository,omitempty"`
+	Commit       string `json:"commit,omitempty"`
+	Reference    string `json:"reference,omitempty"`
+	PrId         string `json:"prId,omitempty"`
+	SourceBranch string `json:"sourceBranch,omitempty"`
+	TargetBranch string `json:"targetBranch,omitempty"`
+	Tag          string `json:"tag,omitempty"`
+}
+
+// NewPayload is a constructor
+func NewPayload(event string, pipeline contract.PipelineInfo) Payload {
+	stages := make([]PayloadStage, 0, len(pipeline.GetStages()))
+	for _, stage := range pipeline.GetStages() {
+		stages = append(stages, PayloadStage{Name: stage.Name, Status: stage.Status})
+	}
+	scmCtx := pipeline.GetSCMContext()
+
+	return Payload{
+		Event:        event,
+		Id:           pipeline.GetId(),
+		Namespace:    pipeline.GetNamespace(),
+		Name:         pipeline.GetInstanceName(),
+		Status:       pipeline.GetStatus(),
+		Description:  pipeline.GetStatus().AsHumanReadableDescription(),
+		DashboardUrl: pipeline.GetDashboardUrl(),
+		Stages:       stages,
+		Scm: PayloadScm{
+			Repository:   scmCtx.RepoHttpsUrl,
+			Commit:       scmCtx.Commit,
+			Reference:    scmCtx.Reference,
+			PrId:         scmCtx.PrId,
+			SourceBranch: scmCtx.SourceBranch,
+			TargetBranch: scmCtx.TargetBranch,
+			Tag:          scmCtx.Tag,
+		},
+	}
+}
@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
+	"github.com/pkg/errors"
+)
+
+const (
+	EventCreated  = "created"
+	EventStarted  = "started"
+	EventProgress = "progress"
+	EventFinished = "finished"
+)
+
+const defaultBody = `{{ .payload | toJson }}`
+const defaultHeaders = `Content-Type: application/json`
+
+type Receiver struct {
+	sc *wiring.ServiceContext
+}
+
+func (wh *Receiver) InitializeWithContext(sc *wiring.ServiceContext) error {
+	sc.Log.Info("Initializing Webhook Receiver")
+	wh.sc = sc
+
+	// register configuration options
+	wh.sc.ConfigSchema.Add(config.Schema{
+		Name: "webhook",
+		AllowedFields: []string{
+			"url",
+			"method",
+			"headers",
+			"body",
+			"events",
+			"hmac-secret",
+			"hmac-secret-name",
+			"hmac-secret-key",
+			"signature-header",
+			"retries",
+			"retry-delay",
+			"retry-max-duration",
+			"timeout",
+			"success-status-codes",
+		},
+	})
+	return nil
+}
+
+func (wh *Receiver) WhenCreated(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return wh.notify(ctx, EventCreated, pipeline, log)
+}
+
+func (wh *Receiver) WhenStarted(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return wh.notify(ctx, EventStarted, pipeline, log)
+}
+
+func (wh *Receiver) UpdateProgress(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return wh.notify(ctx, EventProgress, pipeline, log)
+}
+
+func (wh *Receiver) WhenFinished(ctx context.Context, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	return wh.notify(ctx, EventFinished, pipeline, log)
+}
+
+// notify is rendering the HTTP request from templates and sending it to the endpoint configured for the Pipeline
+func (wh *Receiver) notify(ctx context.Context, event string, pipeline contract.PipelineInfo, log *logging.InternalLogger) error {
+	cfg := wh.sc.Config.FetchContextual("webhook", pipeline.GetNamespace(), pipeline)
+	if cfg.Get("url") == "" {
+		log.Debugf("Skipping webhook, 'webhook.url' is not configured for '%s'", pipeline.GetId())
+		return nil
+	}
+	if !IsEventEnabled(cfg.GetOrDefault("events", "created,started,progress,finished"), event) {
+		return nil
+	}
+
+	request, err := wh.buildRequest(ctx, cfg, event, pipeline)
+	if err != nil {
+		return errors.Wrapf(err, "cannot prepare a webhook request for '%s' event", event)
+	}
+	if err := send(ctx, request, log); err != nil {
+		return errors.Wrapf(err, "cannot send a webhook for '%s' event", event)
+	}
+	return nil
+}
+
+// buildRequest renders all parts of the request and resolves the signing secret
+func (wh *Receiver) buildRequest(ctx context.Context, cfg config.Data, event string, pipeline contract.PipelineInfo) (request, error) {
+	payload := NewPayload(event, pipeline)
+	render := func(part string, templateStr string) (string, error) {
+		return templating.TemplateWebhookPart(templateStr, part, pipeline, event, payload)
+	}
+
+	url, err := render("url", cfg.Get("url"))
+	if err != nil {
+		return request{}, err
+	}
+	body, err := render("body", cfg.GetOrDefault("body", defaultBody))
+	if err != nil {
+		return request{}, err
+	}
+	headers, err := render("headers", cfg.GetOrDefault("headers", defaultHeaders))
+	if err != nil {
+		return request{}, err
+	}
+	parsedHeaders, err := ParseHeaders(headers)
+	if err != nil {
+		return request{}, err
+	}
+	successCodes, err := parseStatusCodes(cfg.GetOrDefault("success-status-codes", "200-299"))
+	if err != nil {
+		return request{}, errors.Wrap(err, "invalid 'webhook.success-status-codes'")
+	}
+	timeout, err := time.ParseDuration(cfg.GetOrDefault("timeout", "10s"))
+	if err != nil {
+		return request{}, errors.Wrap(err, "invalid 'webhook.timeout'")
+	}
+	retryDelay, err := time.ParseDuration(cfg.GetOrDefault("retry-delay", "2s"))
+	if err != nil {
+		return request{}, errors.Wrap(err, "invalid 'webhook.retry-delay'")
+	}
+	retries, err := parsePositiveInt(cfg.GetOrDefault("retries", "3"))
+	if err != nil {
+		return request{}, errors.Wrap(err, "invalid 'webhook.retries'")
+	}
+	retryMaxTime, err := time.ParseDuration(cfg.GetOrDefault("retry-max-duration", "15s"))
+	if err != nil {
+		return request{}, errors.Wrap(err, "invalid 'webhook.retry-max-duration'")
+	}
+
+	// HMAC secret: inline "hmac-secret" or a reference to `kind: Secret` in "hmac-secret-name" and "hmac-secret-key"
+	secret := ""
+	if cfg.HasKey("hmac-secret") || cfg.HasKey("hmac-secret-name") {
+		secret, err = wh.sc.Config.FetchFromFieldOrSecret(ctx, &cfg, pipeline.GetNamespace(), "hmac-secret", "hmac-secret-key", "hmac-secret-name")
+		if err != nil {
+			return request{}, errors.Wrap(err, "cannot fetch HMAC secret")
+		}
+	}
+
+	return request{
+		method:          strings.ToUpper(cfg.GetOrDefault("method", "POST")),
+		url:             strings.TrimSpace(url),
+		body:            []byte(body),
+		headers:         parsedHeaders,
+		secret:          secret,
+		signatureHeader: cfg.GetOrDefault("signature-header", DefaultSignatureHeader),
+		successCodes:    successCodes,
+		timeout:         timeout,
+		retries:         retries,
+		retryDelay:      retryDelay,
+		retryMaxTime:    retryMaxTime,
+	}, nil
+}
+
+func (wh *Receiver) CanHandle(name string) bool {
+	return name == wh.GetImplementationName()
+}
+
+func (wh *Receiver) GetImplementationName() string {
+	return "webhook"
+}
+
+// IsEventEnabled tells if the event is on the comma-separated list of events, e.g. "started,finished"
+func IsEventEnabled(events string, event string) bool {
+	for _, enabled := range strings.Split(events, ",") {
+		if strings.TrimSpace(enabled) == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/config"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract/wiring"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/fake"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/feedback/webhook"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/logging"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func createPipeline() contract.PipelineInfo {
+	return *contract.NewPipelineInfo(
+		contract.JobContext{Commit: "76ea7c7", RepoHttpsUrl: "https://github.com/kube-cicd/pipelines-feedback-core", PrId: "161"},
+		"team-1",
+		"bread-pipeline",
+		"bread-pipeline-abc",
+		time.Now(),
+		[]contract.PipelineStage{{Name: "bake", Status: contract.PipelineSucceeded}},
+		labels.Set{},
+		labels.Set{},
+		&config.Data{},
+	)
+}
+
+func createReceiver(cfg map[string]string) *webhook.Receiver {
+	logger := logging.CreateLogger(true)
+	receiver := &webhook.Receiver{}
+	_ = receiver.InitializeWithContext(&wiring.ServiceContext{
+		Config:       &fake.FieldConfigurationProvider{ConfigurationProvider: fake.ConfigurationProvider{Contextual: config.NewData("webhook", cfg, &fake.NullValidator{}, logger)}},
+		Log:          logger,
+		ConfigSchema: &fake.NullValidator{},
+	})
+	return receiver
+}
+
+func TestReceiver_SendsDefaultPayloadWithSignature(t *testing.T) {
+	var received []byte
+	var signature string
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(webhook.DefaultSignatureHeader)
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{
+		"url":         server.URL + "/hooks/{{ .pipeline.GetNamespace }}",
+		"hmac-secret": "bakery",
+	})
+	err := receiver.WhenFinished(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.Nil(t, err)
+	assert.Equal(t, webhook.Sign("bakery", received), signature)
+	assert.Equal(t, "application/json", contentType)
+
+	payload := webhook.Payload{}
+	assert.Nil(t, json.Unmarshal(received, &payload))
+	assert.Equal(t, "finished", payload.Event)
+	assert.Equal(t, contract.PipelineSucceeded, payload.Status)
+	assert.Equal(t, "161", payload.Scm.PrId)
+	assert.Equal(t, "bake", payload.Stages[0].Name)
+}
+
+func TestReceiver_RendersUrlHeadersAndBody(t *testing.T) {
+	var path, header, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		path, header, body = r.URL.Path, r.Header.Get("X-Pipeline"), string(raw)
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{
+		"url":     server.URL + "/{{ .pipeline.GetNamespace }}/{{ .event }}",
+		"headers": "X-Pipeline: {{ .pipeline.GetInstanceName }}\nContent-Type: text/plain",
+		"body":    "{{ .pipeline.GetInstanceName }} {{ .pipeline.GetStatus.AsHumanReadableDescription }}",
+	})
+	err := receiver.UpdateProgress(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/team-1/progress", path)
+	assert.Equal(t, "bread-pipeline-abc", header)
+	assert.Equal(t, "bread-pipeline-abc succeeded", body)
+}
+
+func TestReceiver_RetriesOnServerError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{"url": server.URL, "retries": "2", "retry-delay": "1ms"})
+	err := receiver.WhenStarted(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestReceiver_StopsRetryingAfterMaxDuration(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{"url": server.URL, "retries": "1000", "retry-delay": "20ms", "retry-max-duration": "100ms"})
+	started := time.Now()
+	err := receiver.WhenStarted(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.NotNil(t, err, "the error is returned, so the controller requeues the object")
+	assert.Less(t, time.Since(started), time.Second)
+	assert.Less(t, atomic.LoadInt32(&calls), int32(10))
+}
+
+func TestReceiver_FailsOnUnlistedStatusCode(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{"url": server.URL, "success-status-codes": "200,204", "retry-delay": "1ms"})
+	err := receiver.WhenCreated(context.TODO(), createPipeline(), logging.CreateLogger(true))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "HTTP 202")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestReceiver_SkipsDisabledEvents(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	receiver := createReceiver(map[string]string{"url": server.URL, "events": "finished"})
+	assert.Nil(t, receiver.UpdateProgress(context.TODO(), createPipeline(), logging.CreateLogger(true)))
+	assert.Nil(t, receiver.WhenFinished(context.TODO(), createPipeline(), logging.CreateLogger(true)))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,51 @@
+package githubapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// CallAPI performs a GitHub REST API request authenticated with a bearer token (an App JWT or an installation token).
+// The "in" is encoded as a JSON body, when not nil. A successful JSON response is decoded into "out"
+func CallAPI(ctx context.Context, client *http.Client, apiUrl string, token string, method string, path string,
+	in interface{}, out interface{}) error {
+
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return errors.Wrapf(err, "cannot encode GitHub API '%s' request", path)
+		}
+		body = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiUrl+path, body)
+	if err != nil {
+		return errors.Wrap(err, "cannot create HTTP request")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "cannot call GitHub API '%s'", path)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return errors.New(fmt.Sprintf("GitHub API '%s' responded with HTTP %v: %s", path, response.StatusCode, string(responseBody)))
+	}
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+		return errors.Wrapf(err, "cannot decode GitHub API '%s' response", path)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package githubapp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/githubapp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallAPI_SendsJsonBodyWithToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/kube-cicd/bakery/check-runs", r.URL.Path)
+		assert.Equal(t, "Bearer ghs_bakery", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body := map[string]string{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "bake", body["name"])
+		_, _ = w.Write([]byte(`{"id": 161}`))
+	}))
+	defer server.Close()
+
+	out := struct {
+		Id int `json:"id"`
+	}{}
+	err := githubapp.CallAPI(context.TODO(), server.Client(), server.URL, "ghs_bakery", http.MethodPost,
+		"/repos/kube-cicd/bakery/check-runs", map[string]string{"name": "bake"}, &out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 161, out.Id)
+}
+
+func TestCallAPI_ReturnsErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	}))
+	defer server.Close()
+
+	err := githubapp.CallAPI(context.TODO(), server.Client(), server.URL, "ghs_bakery", http.MethodGet,
+		"/app/installations", nil, &struct{}{})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "HTTP 403: {\"message\": \"Resource not accessible by integration\"}")
+}
@@ -0,0 +1,176 @@
+package githubapp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	"github.com/pkg/errors"
+)
+
+const DefaultApiUrl = "https://api.github.com"
+
+// App authenticates as a GitHub App: a JWT signed with the App's private key is exchanged for installation tokens.
+// Installation ids and tokens are cached in the store, so the GitHub API is asked again only after the token expires
+type App struct {
+	Id         string
+	PrivateKey *rsa.PrivateKey
+
+	// ApiUrl is e.g. https://api.github.com or https://github.example.org/api/v3 for GitHub Enterprise
+	ApiUrl string
+
+	// Store is optional, without it every call creates a new installation token
+	Store  *store.Operator
+	Client *http.Client
+}
+
+type installation struct {
+	Id int64 `json:"id"`
+}
+
+type accessToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewApp creates an App from its id and a PEM encoded private key (PKCS#1 as generated by GitHub, or PKCS#8)
+func NewApp(appId string, privateKeyPem string, apiUrl string, operator *store.Operator) (*App, error) {
+	if strings.TrimSpace(appId) == "" {
+		return nil, errors.New("GitHub App id is not specified")
+	}
+	privateKey, err := ParsePrivateKey(privateKeyPem)
+	if err != nil {
+		return nil, err
+	}
+	if apiUrl == "" {
+		apiUrl = DefaultApiUrl
+	}
+	return &App{
+		Id:         strings.TrimSpace(appId),
+		PrivateKey: privateKey,
+		ApiUrl:     strings.TrimRight(apiUrl, "/"),
+		Store:      operator,
+		Client:     &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// ParsePrivateKey decodes a PEM encoded RSA private key
+func ParsePrivateKey(privateKeyPem string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(privateKeyPem)))
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not a valid PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse GitHub App private key")
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// ApiUrlFromRepository returns the API url of GitHub or GitHub Enterprise basing on the repository url
+func ApiUrlFromRepository(repoHttpsUrl string) string {
+	u, err := url.Parse(repoHttpsUrl)
+	if err != nil || u.Host == "" || u.Host == "github.com" || u.Host == "www.github.com" {
+		return DefaultApiUrl
+	}
+	return "https://" + u.Host + "/api/v3"
+}
+
+// CreateJWT creates a token that authenticates as the App itself. It is valid for up to 10 minutes, the issue time
+// is set in the past to tolerate a clock drift
+func (a *App) CreateJWT(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.Id,
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", errors.Wrap(err, "cannot sign GitHub App JWT")
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// GetRepositoryToken returns an installation token for the installation that covers the repository
+func (a *App) GetRepositoryToken(ctx context.Context, owner string, repository string) (string, error) {
+	installationId, err := a.FindInstallationId(ctx, owner, repository)
+	if err != nil {
+		return "", err
+	}
+	return a.GetInstallationToken(ctx, installationId)
+}
+
+// FindInstallationId resolves the installation of the App in the account (organization or user) owning the repository
+func (a *App) FindInstallationId(ctx context.Context, owner string, repository string) (string, error) {
+	if a.Store != nil {
+		if cached := a.Store.GetAppInstallationId(a.cacheKey(), owner); cached != "" {
+			return cached, nil
+		}
+	}
+	found := installation{}
+	if err := a.call(ctx, http.MethodGet, "/repos/"+owner+"/"+repository+"/installation", &found); err != nil {
+		return "", errors.Wrapf(err, "cannot find GitHub App installation for '%s/%s'", owner, repository)
+	}
+	installationId := strconv.FormatInt(found.Id, 10)
+	if a.Store != nil {
+		a.Store.RecordAppInstallationId(a.cacheKey(), owner, installationId)
+	}
+	return installationId, nil
+}
+
+// GetInstallationToken returns a cached installation token, or exchanges the JWT for a new one
+func (a *App) GetInstallationToken(ctx context.Context, installationId string) (string, error) {
+	tokenKey := a.cacheKey() + "/" + installationId
+	if a.Store != nil {
+		if cached := a.Store.GetAccessToken(tokenKey); cached != "" {
+			return cached, nil
+		}
+	}
+	created := accessToken{}
+	if err := a.call(ctx, http.MethodPost, "/app/installations/"+installationId+"/access_tokens", &created); err != nil {
+		return "", errors.Wrapf(err, "cannot create GitHub App installation token for installation '%s'", installationId)
+	}
+	if created.Token == "" {
+		return "", errors.New("GitHub returned an empty installation token")
+	}
+	if a.Store != nil {
+		a.Store.RecordAccessToken(tokenKey, created.Token, created.ExpiresAt)
+	}
+	return created.Token, nil
+}
+
+// call performs a request authenticated as the App
+func (a *App) call(ctx context.Context, method string, path string, out interface{}) error {
+	jwt, err := a.CreateJWT(time.Now())
+	if err != nil {
+		return err
+	}
+	return CallAPI(ctx, a.Client, a.ApiUrl, jwt, method, path, nil, out)
+}
+
+func (a *App) cacheKey() string {
+	return "github/" + a.ApiUrl + "/" + a.Id
+}
@@ -0,0 +1,107 @@
+package githubapp_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/githubapp"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func createPrivateKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func TestApp_CreateJWT_IsSignedWithPrivateKey(t *testing.T) {
+	key, keyPem := createPrivateKey(t)
+	app, err := githubapp.NewApp("161", keyPem, "", nil)
+	assert.Nil(t, err)
+
+	now := time.Unix(1700000000, 0)
+	jwt, err := app.CreateJWT(now)
+	assert.Nil(t, err)
+
+	parts := strings.Split(jwt, ".")
+	assert.Len(t, parts, 3)
+	claims := map[string]interface{}{}
+	decoded, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	_ = json.Unmarshal(decoded, &claims)
+	assert.Equal(t, "161", claims["iss"])
+	assert.Equal(t, float64(now.Unix()-60), claims["iat"])
+	assert.Equal(t, float64(now.Add(9*time.Minute).Unix()), claims["exp"])
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.Nil(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func TestApp_GetRepositoryToken_IsCachedUntilExpiry(t *testing.T) {
+	_, keyPem := createPrivateKey(t)
+	var lock sync.Mutex
+	requests := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		lock.Unlock()
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ey"))
+
+		switch r.URL.Path {
+		case "/repos/kube-cicd/bakery/installation":
+			_, _ = w.Write([]byte(`{"id": 4815162342}`))
+		case "/app/installations/4815162342/access_tokens":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"token":      "ghs_bakery",
+				"expires_at": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	app, err := githubapp.NewApp("161", keyPem, server.URL, &store.Operator{Store: store.NewMemory()})
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		token, tokenErr := app.GetRepositoryToken(context.TODO(), "kube-cicd", "bakery")
+		assert.Nil(t, tokenErr)
+		assert.Equal(t, "ghs_bakery", token)
+	}
+	assert.Equal(t, []string{
+		"GET /repos/kube-cicd/bakery/installation",
+		"POST /app/installations/4815162342/access_tokens",
+	}, requests)
+}
+
+func TestApp_GetRepositoryToken_NotInstalled(t *testing.T) {
+	_, keyPem := createPrivateKey(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	app, _ := githubapp.NewApp("161", keyPem, server.URL, nil)
+	_, err := app.GetRepositoryToken(context.TODO(), "kube-cicd", "bakery")
+	assert.ErrorContains(t, err, "cannot find GitHub App installation for 'kube-cicd/bakery'")
+}
+
+func TestApiUrlFromRepository(t *testing.T) {
+	assert.Equal(t, "https://api.github.com", githubapp.ApiUrlFromRepository("https://github.com/kube-cicd/bakery"))
+	assert.Equal(t, "https://github.example.org/api/v3", githubapp.ApiUrlFromRepository("https://github.example.org/kube-cicd/bakery"))
+}
@@ -16,6 +16,8 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	v1 "k8s.io/client-go/kubernetes/typed/batch/v1"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
 	"time"
 )
 
@@ -25,6 +27,8 @@ type BatchV1JobProvider struct {
 	store         *store.Operator
 	logger        *logging.InternalLogger
 	confProvider  config.ConfigurationProviderInterface
+	ownerFetcher  k8s.OwnerMetadataFetcher
+	conventions   contract.Conventions
 }
 
 func (bjp *BatchV1JobProvider) InitializeWithContext(sc *wiring.ServiceContext) error {
@@ -38,9 +42,17 @@ func (bjp *BatchV1JobProvider) InitializeWithContext(sc *wiring.ServiceContext)
 		return errors.Wrap(err, "cannot initialize BatchV1JobProvider")
 	}
 	bjp.coreV1Client = coreClient
+	ownerReader := sc.KubeClient
+	if ownerReader == nil {
+		if ownerReader, err = ctrlclient.New(sc.KubeConfig, ctrlclient.Options{}); err != nil {
+			return errors.Wrap(err, "cannot initialize BatchV1JobProvider")
+		}
+	}
+	bjp.ownerFetcher = k8s.NewOwnerMetadataFetcher(ownerReader)
 	bjp.store = sc.Store
 	bjp.logger = sc.Log
 	bjp.confProvider = sc.Config
+	bjp.conventions = sc.Conventions
 	return nil
 }
 
@@ -54,16 +66,19 @@ func (bjp *BatchV1JobProvider) ReceivePipelineInfo(ctx context.Context, name str
 		return contract.PipelineInfo{}, errors.Wrap(err, "cannot fetch batch/v1 Job")
 	}
 
+	// collect SCM context, optionally inheriting missing information from the owners (e.g. a CronJob)
+	maxDepth, _ := strconv.Atoi(globalCfg.GetOrDefault("owner-references-max-depth", "0"))
+	scm, err := k8s.CreateJobContextInheritedFromOwners(ctx, job.ObjectMeta, bjp.conventions, bjp.ownerFetcher, maxDepth)
+	if err != nil {
+		return contract.PipelineInfo{}, err
+	}
+
 	// validate
-	if ok, err := k8s.HasUsableAnnotations(job.ObjectMeta); !ok {
-		if err != nil {
-			return contract.PipelineInfo{}, err
-		}
+	if !scm.IsValid() {
 		return contract.PipelineInfo{}, errors.New(provider.ErrNotMatched)
 	}
 
 	// translate its status
-	scm, _ := k8s.CreateJobContextFromKubernetesAnnotations(job.ObjectMeta)
 	jobStatus := translateJobStatus(job)
 
 	// start time
@@ -71,6 +86,7 @@ func (bjp *BatchV1JobProvider) ReceivePipelineInfo(ctx context.Context, name str
 	if job.Status.StartTime != nil {
 		startTime = job.Status.StartTime.Time
 	}
+	finishTime := findJobFinishTime(job)
 
 	dashboardUrl, dashboardTplErr := templating.TemplateDashboardUrl(globalCfg.Get("dashboard-url"), job, job.TypeMeta)
 	if dashboardTplErr != nil {
@@ -88,7 +104,7 @@ func (bjp *BatchV1JobProvider) ReceivePipelineInfo(ctx context.Context, name str
 		string(job.UID),
 		startTime,
 		[]contract.PipelineStage{
-			{Name: "job/" + job.Name, Status: jobStatus},
+			{Name: "job/" + job.Name, Status: jobStatus, StartedAt: startTime, FinishedAt: finishTime},
 		},
 		labels.Set(job.Labels),
 		labels.Set(job.Annotations),
@@ -107,6 +123,19 @@ func (bjp *BatchV1JobProvider) fetchLogs(ctx context.Context, job *v1model.Job,
 	)
 }
 
+// findJobFinishTime returns a completion time of a succeeded Job, or a time when the Job was marked as failed
+func findJobFinishTime(job *v1model.Job) time.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == v1model.JobFailed && condition.Status == "True" {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
 // translateJobStatus translates status from batch/v1 Job format to contract.Status
 func translateJobStatus(job *v1model.Job) contract.Status {
 	if job.Status.Failed > 0 {
@@ -7,49 +7,85 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// HasUsableAnnotations is checking if Kubernetes object is usable at all
+// HasUsableAnnotations is checking if Kubernetes object is usable at all, using contract.DefaultConventions()
 func HasUsableAnnotations(meta metav1.ObjectMeta) (bool, error) {
-	scm, err := CreateJobContextFromKubernetesAnnotations(meta)
+	return HasUsableAnnotationsWithConventions(meta, contract.DefaultConventions())
+}
+
+// HasUsableAnnotationsWithConventions is checking if Kubernetes object is usable at all, using annotation names of given controller instance
+func HasUsableAnnotationsWithConventions(meta metav1.ObjectMeta, conventions contract.Conventions) (bool, error) {
+	scm, err := CreateJobContextWithConventions(meta, conventions)
 	if err != nil {
 		return false, err
 	}
 	return scm.IsValid(), nil
 }
 
-// CreateJobContextFromKubernetesAnnotations translates any Kubernetes object into contract.JobContext
+// CreateJobContextFromKubernetesAnnotations translates any Kubernetes object into contract.JobContext,
+// using contract.DefaultConventions()
 func CreateJobContextFromKubernetesAnnotations(meta metav1.ObjectMeta) (contract.JobContext, error) {
+	return CreateJobContextWithConventions(meta, contract.DefaultConventions())
+}
+
+// CreateJobContextWithConventions translates any Kubernetes object into contract.JobContext
+// using annotation names of given controller instance
+func CreateJobContextWithConventions(meta metav1.ObjectMeta, conventions contract.Conventions) (contract.JobContext, error) {
 	isTechnicalJob := false
 	techJob := ""
-	if val, exists := meta.Annotations[contract.GetTechnicalJobAnnotation()]; exists {
-		logrus.Debugf("Has '%s'", contract.GetTechnicalJobAnnotation())
+	if val, exists := meta.Annotations[conventions.GetTechnicalJobAnnotation()]; exists {
+		logrus.Debugf("Has '%s'", conventions.GetTechnicalJobAnnotation())
 		isTechnicalJob = true
 		techJob = val
 	}
 
 	repoHttpsUrl := ""
-	if val, exists := meta.Annotations[contract.GetHttpsRepoUrlAnnotation()]; exists {
-		logrus.Debugf("Has '%s'", contract.GetHttpsRepoUrlAnnotation())
+	if val, exists := meta.Annotations[conventions.GetHttpsRepoUrlAnnotation()]; exists {
+		logrus.Debugf("Has '%s'", conventions.GetHttpsRepoUrlAnnotation())
 		repoHttpsUrl = val
 	}
 
-	scm, err := contract.NewSCMContext(repoHttpsUrl)
+	scmOptions := make([]func(opts *contract.SCMContextOptions), 0)
+	if val, exists := meta.Annotations[conventions.GetHttpsRepoHostAnnotation()]; exists {
+		logrus.Debugf("Has '%s'", conventions.GetHttpsRepoHostAnnotation())
+		scmOptions = append(scmOptions, contract.SCMContextWithHttpsHost(val))
+	}
+
+	scm, err := contract.NewSCMContext(repoHttpsUrl, scmOptions...)
 	if err != nil && !isTechnicalJob {
 		return scm, errors.Wrap(err, "cannot create JobContext")
 	}
 
 	scm.TechnicalJob = techJob
 
-	if val, exists := meta.Annotations[contract.GetPrIdAnnotation()]; exists {
-		logrus.Debugf("Has '%s'", contract.GetPrIdAnnotation())
+	if val, exists := meta.Annotations[conventions.GetPrIdAnnotation()]; exists {
+		logrus.Debugf("Has '%s'", conventions.GetPrIdAnnotation())
 		scm.PrId = val
 	}
-	if val, exists := meta.Annotations[contract.GetRefAnnotation()]; exists {
-		logrus.Debugf("Has '%s'", contract.GetRefAnnotation())
+	if val, exists := meta.Annotations[conventions.GetRefAnnotation()]; exists {
+		logrus.Debugf("Has '%s'", conventions.GetRefAnnotation())
 		scm.Reference = val
 	}
-	if val, exists := meta.Annotations[contract.GetCommmitAnnotation()]; exists {
-		logrus.Debugf("Has '%s'", contract.GetCommmitAnnotation())
+	if val, exists := meta.Annotations[conventions.GetCommmitAnnotation()]; exists {
+		logrus.Debugf("Has '%s'", conventions.GetCommmitAnnotation())
 		scm.Commit = val
 	}
-	return scm, nil
+
+	// optional GIT metadata
+	optional := map[string]*string{
+		conventions.GetSourceBranchAnnotation():   &scm.SourceBranch,
+		conventions.GetTargetBranchAnnotation():   &scm.TargetBranch,
+		conventions.GetTagAnnotation():            &scm.Tag,
+		conventions.GetCommitAuthorAnnotation():   &scm.CommitAuthor,
+		conventions.GetCommitTitleAnnotation():    &scm.CommitTitle,
+		conventions.GetProjectIdAnnotation():      &scm.ProjectId,
+		conventions.GetEnvironmentAnnotation():    &scm.Environment,
+		conventions.GetEnvironmentUrlAnnotation(): &scm.EnvironmentUrl,
+	}
+	for annotation, field := range optional {
+		if val, exists := meta.Annotations[annotation]; exists {
+			logrus.Debugf("Has '%s'", annotation)
+			*field = val
+		}
+	}
+	return scm.WithReferenceDerivedFields(), nil
 }
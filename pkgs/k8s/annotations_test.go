@@ -1,6 +1,7 @@
 package k8s_test
 
 import (
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/k8s"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +22,62 @@ type TestCase struct {
 	expectedIsValid          bool
 }
 
+func TestCreateJobContextFromKubernetesAnnotations_GitMetadata(t *testing.T) {
+	scm, err := k8s.CreateJobContextWithConventions(metav1.ObjectMeta{
+		Name: "release-1",
+		Annotations: map[string]string{
+			"pipelinesfeedback.keskad.pl/https-repo-url": "https://gitlab.com/kube-cicd/pipelines-feedback-core.git",
+			"pipelinesfeedback.keskad.pl/commit":         "2d6cc283fb5be9f963f2b70c504e4fedc6c025b8",
+			"pipelinesfeedback.keskad.pl/ref":            "refs/tags/v1.6.1",
+			"pipelinesfeedback.keskad.pl/commit-author":  "Emma Goldman",
+			"pipelinesfeedback.keskad.pl/commit-title":   "Living My Life",
+			"pipelinesfeedback.keskad.pl/project-id":     "1869",
+		},
+	}, contract.DefaultConventions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.6.1", scm.Tag, "Tag should be derived from the reference")
+	assert.True(t, scm.IsTag())
+	assert.Equal(t, "", scm.SourceBranch)
+	assert.Equal(t, "Emma Goldman", scm.CommitAuthor)
+	assert.Equal(t, "Living My Life", scm.CommitTitle)
+	assert.Equal(t, "1869", scm.ProjectId)
+}
+
+func TestCreateJobContextFromKubernetesAnnotations_Environment(t *testing.T) {
+	scm, err := k8s.CreateJobContextWithConventions(metav1.ObjectMeta{
+		Name: "deploy-1",
+		Annotations: map[string]string{
+			"pipelinesfeedback.keskad.pl/https-repo-url":  "https://github.com/kube-cicd/pipelines-feedback-core.git",
+			"pipelinesfeedback.keskad.pl/commit":          "2d6cc283fb5be9f963f2b70c504e4fedc6c025b8",
+			"pipelinesfeedback.keskad.pl/environment":     "production",
+			"pipelinesfeedback.keskad.pl/environment-url": "https://bakery.example.org",
+		},
+	}, contract.DefaultConventions())
+
+	assert.Nil(t, err)
+	assert.True(t, scm.IsDeployment())
+	assert.Equal(t, "production", scm.Environment)
+	assert.Equal(t, "https://bakery.example.org", scm.EnvironmentUrl)
+}
+
+func TestCreateJobContextFromKubernetesAnnotations_BranchesFromAnnotationsTakePrecedence(t *testing.T) {
+	scm, err := k8s.CreateJobContextWithConventions(metav1.ObjectMeta{
+		Name: "pr-1",
+		Annotations: map[string]string{
+			"pipelinesfeedback.keskad.pl/https-repo-url": "https://gitlab.com/kube-cicd/pipelines-feedback-core.git",
+			"pipelinesfeedback.keskad.pl/pr-id":          "1",
+			"pipelinesfeedback.keskad.pl/ref":            "refs/heads/feature-x",
+			"pipelinesfeedback.keskad.pl/target-branch":  "main",
+		},
+	}, contract.DefaultConventions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "feature-x", scm.SourceBranch, "Source branch should be derived from the reference")
+	assert.Equal(t, "main", scm.TargetBranch)
+	assert.False(t, scm.IsTag())
+}
+
 func TestCreateJobContextFromKubernetesAnnotations(t *testing.T) {
 	testCases := []TestCase{
 		{
@@ -41,6 +98,23 @@ func TestCreateJobContextFromKubernetesAnnotations(t *testing.T) {
 			expectedIsTechnicalJob:   false,
 			expectedIsValid:          true,
 		},
+		{
+			name: "SSH url with a HTTPS host override",
+			inputAnnotations: map[string]string{
+				"pipelinesfeedback.keskad.pl/commit":          "2d6cc283fb5be9f963f2b70c504e4fedc6c025b8",
+				"pipelinesfeedback.keskad.pl/https-repo-url":  "git@gitlab.example.org:kube-cicd/core/pipelines-feedback-core.git",
+				"pipelinesfeedback.keskad.pl/https-repo-host": "gitlab.example.org:8443",
+			},
+			expectedError:            "",
+			expectedPrId:             "",
+			expectedCommit:           "2d6cc283fb5be9f963f2b70c504e4fedc6c025b8",
+			expectedReference:        "",
+			expectedRepoHttpsUrl:     "https://gitlab.example.org:8443/kube-cicd/core/pipelines-feedback-core.git",
+			expectedOrganizationName: "kube-cicd/core",
+			expectedRepositoryName:   "pipelines-feedback-core",
+			expectedIsTechnicalJob:   false,
+			expectedIsValid:          true,
+		},
 		{
 			name: "Its a technical job, without SCM context",
 			inputAnnotations: map[string]string{
@@ -106,8 +180,8 @@ func TestCreateJobContextFromKubernetesAnnotations(t *testing.T) {
 			Name:        "bakunin-1",
 			Annotations: testCase.inputAnnotations,
 		}
-		k8sContext, err := k8s.CreateJobContextFromKubernetesAnnotations(objectMeta)
-		hasUsableAnnotations, _ := k8s.HasUsableAnnotations(objectMeta)
+		k8sContext, err := k8s.CreateJobContextWithConventions(objectMeta, contract.DefaultConventions())
+		hasUsableAnnotations, _ := k8s.HasUsableAnnotationsWithConventions(objectMeta, contract.DefaultConventions())
 
 		if err == nil {
 			assert.Equal(t, testCase.expectedCommit, k8sContext.Commit)
@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OwnerMetadataFetcher retrieves .metadata of an object referenced in .metadata.ownerReferences
+type OwnerMetadataFetcher func(ctx context.Context, namespace string, ref metav1.OwnerReference) (metav1.ObjectMeta, error)
+
+// ownerFetchTimeout limits waiting for the API, a slow owner lookup should not block the reconciliation
+const ownerFetchTimeout = 10 * time.Second
+
+// NewOwnerMetadataFetcher creates a fetcher that is able to retrieve metadata of any kind, including CRDs.
+// The reader should not be cached: a cached client starts a cluster-wide informer for each owner kind,
+// which waits for the whole timeout when RBAC does not allow to list or watch the kind
+func NewOwnerMetadataFetcher(reader client.Reader) OwnerMetadataFetcher {
+	return func(ctx context.Context, namespace string, ref metav1.OwnerReference) (metav1.ObjectMeta, error) {
+		gv, gvErr := schema.ParseGroupVersion(ref.APIVersion)
+		if gvErr != nil {
+			return metav1.ObjectMeta{}, errors.Wrapf(gvErr, "invalid apiVersion '%s' in owner reference", ref.APIVersion)
+		}
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(gv.WithKind(ref.Kind))
+
+		fetchCtx, cancel := context.WithTimeout(ctx, ownerFetchTimeout)
+		defer cancel()
+		if err := reader.Get(fetchCtx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, obj); err != nil {
+			return metav1.ObjectMeta{}, errors.Wrapf(err, "cannot fetch owner '%s/%s'", ref.Kind, ref.Name)
+		}
+		return obj.ObjectMeta, nil
+	}
+}
+
+// CreateJobContextInheritedFromOwners creates a contract.JobContext from object annotations, then walks up
+// the .metadata.ownerReferences (up to maxDepth levels) filling missing fields with values found on the ancestors.
+// Owners that cannot be fetched are ending the walk
+func CreateJobContextInheritedFromOwners(ctx context.Context, meta metav1.ObjectMeta, conventions contract.Conventions,
+	fetcher OwnerMetadataFetcher, maxDepth int) (contract.JobContext, error) {
+
+	scm, err := CreateJobContextWithConventions(meta, conventions)
+	if err != nil {
+		return scm, err
+	}
+
+	current := meta
+	for depth := 0; depth < maxDepth; depth++ {
+		ref := pickOwnerReference(current.OwnerReferences)
+		if ref == nil {
+			break
+		}
+		owner, fetchErr := fetcher(ctx, meta.Namespace, *ref)
+		if fetchErr != nil {
+			// e.g. no RBAC permissions to a given kind. Do not block the object from being processed
+			logrus.Warningf("Cannot inherit JobContext from owner: %s", fetchErr.Error())
+			break
+		}
+		ownerScm, ownerErr := CreateJobContextWithConventions(owner, conventions)
+		if ownerErr != nil {
+			logrus.Debugf("Owner '%s/%s' has invalid annotations: %s", ref.Kind, ref.Name, ownerErr.Error())
+		} else {
+			scm = scm.InheritFrom(ownerScm)
+		}
+		current = owner
+	}
+	return scm, nil
+}
+
+// pickOwnerReference prefers the managing controller, falls back to the first owner
+func pickOwnerReference(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range refs {
+		if refs[i].Controller != nil && *refs[i].Controller {
+			return &refs[i]
+		}
+	}
+	if len(refs) > 0 {
+		return &refs[0]
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package k8s_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/k8s"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func createOwnersFetcher(owners map[string]metav1.ObjectMeta) k8s.OwnerMetadataFetcher {
+	return func(ctx context.Context, namespace string, ref metav1.OwnerReference) (metav1.ObjectMeta, error) {
+		if owner, exists := owners[ref.Kind+"/"+ref.Name]; exists {
+			return owner, nil
+		}
+		return metav1.ObjectMeta{}, errors.New("not found")
+	}
+}
+
+func TestCreateJobContextInheritedFromOwners_InheritsFromCronJob(t *testing.T) {
+	isController := true
+	fetcher := createOwnersFetcher(map[string]metav1.ObjectMeta{
+		"CronJob/backup": {
+			Name: "backup",
+			Annotations: map[string]string{
+				"pipelinesfeedback.keskad.pl/https-repo-url": "https://github.com/kube-cicd/pipelines-feedback-core.git",
+				"pipelinesfeedback.keskad.pl/ref":            "refs/heads/main",
+			},
+		},
+	})
+	job := metav1.ObjectMeta{
+		Name: "backup-28192",
+		Annotations: map[string]string{
+			"pipelinesfeedback.keskad.pl/commit": "2d6cc283fb5be9f963f2b70c504e4fedc6c025b8",
+		},
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", Controller: &isController},
+		},
+	}
+
+	scm, err := k8s.CreateJobContextInheritedFromOwners(context.TODO(), job, contract.DefaultConventions(), fetcher, 1)
+
+	assert.Nil(t, err)
+	assert.True(t, scm.IsValid())
+	assert.Equal(t, "2d6cc283fb5be9f963f2b70c504e4fedc6c025b8", scm.Commit, "Own annotations should not be overridden")
+	assert.Equal(t, "refs/heads/main", scm.Reference)
+	assert.Equal(t, "kube-cicd", scm.OrganizationName)
+	assert.Equal(t, "pipelines-feedback-core", scm.RepositoryName)
+}
+
+func TestCreateJobContextInheritedFromOwners_RespectsMaxDepth(t *testing.T) {
+	fetcher := createOwnersFetcher(map[string]metav1.ObjectMeta{
+		"CronJob/backup": {
+			Name: "backup",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "example.org/v1", Kind: "Backup", Name: "nightly"},
+			},
+		},
+		"Backup/nightly": {
+			Name: "nightly",
+			Annotations: map[string]string{
+				"pipelinesfeedback.keskad.pl/technical-job": "nightly-backup",
+			},
+		},
+	})
+	job := metav1.ObjectMeta{
+		Name: "backup-28192",
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup"},
+		},
+	}
+
+	disabled, err := k8s.CreateJobContextInheritedFromOwners(context.TODO(), job, contract.DefaultConventions(), fetcher, 0)
+	assert.Nil(t, err)
+	assert.False(t, disabled.IsValid(), "Inheritance is disabled with depth=0")
+
+	tooShallow, err := k8s.CreateJobContextInheritedFromOwners(context.TODO(), job, contract.DefaultConventions(), fetcher, 1)
+	assert.Nil(t, err)
+	assert.False(t, tooShallow.IsTechnicalJob(), "The annotation is on the second level, should not be reached")
+
+	deep, err := k8s.CreateJobContextInheritedFromOwners(context.TODO(), job, contract.DefaultConventions(), fetcher, 2)
+	assert.Nil(t, err)
+	assert.True(t, deep.IsTechnicalJob())
+	assert.Equal(t, "nightly-backup", deep.TechnicalJob)
+}
+
+func TestCreateJobContextInheritedFromOwners_OwnerNotFound(t *testing.T) {
+	job := metav1.ObjectMeta{
+		Name: "backup-28192",
+		Annotations: map[string]string{
+			"pipelinesfeedback.keskad.pl/technical-job": "backup",
+		},
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "batch/v1", Kind: "CronJob", Name: "deleted"},
+		},
+	}
+
+	scm, err := k8s.CreateJobContextInheritedFromOwners(context.TODO(), job, contract.DefaultConventions(), createOwnersFetcher(nil), 3)
+	assert.Nil(t, err, "Not accessible owner should not block the object from processing")
+	assert.Equal(t, "backup", scm.TechnicalJob)
+}
+
+func TestNewOwnerMetadataFetcher_FetchesOnlyMetadata(t *testing.T) {
+	reader := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(&batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "backup",
+			Namespace:   "team-1",
+			Annotations: map[string]string{"pipelinesfeedback.keskad.pl/ref": "refs/heads/main"},
+		},
+	}).Build()
+	fetcher := k8s.NewOwnerMetadataFetcher(reader)
+
+	owner, err := fetcher(context.TODO(), "team-1", metav1.OwnerReference{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup"})
+	assert.Nil(t, err)
+	assert.Equal(t, "refs/heads/main", owner.Annotations["pipelinesfeedback.keskad.pl/ref"])
+
+	_, err = fetcher(context.TODO(), "team-2", metav1.OwnerReference{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup"})
+	assert.NotNil(t, err)
+}
@@ -10,3 +10,13 @@ type Store interface {
 	Get(key string) (string, error)
 	Initialize() error
 }
+
+// HashStore is optionally implemented by a Store that can update single fields of a hash atomically.
+// Stores not implementing it are still supported, the Operator then keeps a hash as a single serialized value
+type HashStore interface {
+	// SetHashField atomically sets a single field of a hash, other fields are kept. The ttl applies to the whole hash
+	SetHashField(key string, field string, value string, ttl int) error
+	// GetHash returns all fields of a hash
+	GetHash(key string) (map[string]string, error)
+	DeleteHashFields(key string, fields ...string) error
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"github.com/pkg/errors"
+	"sync"
 	"time"
 )
 
@@ -10,25 +11,32 @@ type MemEntry struct {
 	expires time.Time
 }
 
+type memHash struct {
+	fields  map[string]string
+	expires time.Time
+}
+
 type Memory struct {
-	mem map[string]MemEntry
+	mu     sync.Mutex
+	mem    map[string]MemEntry
+	hashes map[string]memHash
 }
 
 func (m *Memory) Set(key, value string, ttl int) error {
-	if ttl == 0 {
-		ttl = 86400 * 365 * 10 // 10 years should be enough
-	}
-	expires := time.Now()
-	expires = expires.Add(time.Second * time.Duration(ttl))
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	m.mem[key] = MemEntry{
 		val:     value,
-		expires: expires,
+		expires: expiresAfter(ttl),
 	}
 	return nil
 }
 
 func (m *Memory) Get(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if entry, ok := m.mem[key]; ok {
 		if entry.expires.Before(time.Now()) {
 			return "", errors.New(ErrNotFound)
@@ -38,6 +46,47 @@ func (m *Memory) Get(key string) (string, error) {
 	return "", errors.New(ErrNotFound)
 }
 
+func (m *Memory) SetHashField(key string, field string, value string, ttl int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash, ok := m.hashes[key]
+	if !ok || hash.expires.Before(time.Now()) {
+		hash = memHash{fields: make(map[string]string)}
+	}
+	hash.fields[field] = value
+	hash.expires = expiresAfter(ttl)
+	m.hashes[key] = hash
+	return nil
+}
+
+func (m *Memory) GetHash(key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash, ok := m.hashes[key]
+	if !ok || hash.expires.Before(time.Now()) || len(hash.fields) == 0 {
+		return map[string]string{}, errors.New(ErrNotFound)
+	}
+	fields := make(map[string]string, len(hash.fields))
+	for field, value := range hash.fields {
+		fields[field] = value
+	}
+	return fields, nil
+}
+
+func (m *Memory) DeleteHashFields(key string, fields ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if hash, ok := m.hashes[key]; ok {
+		for _, field := range fields {
+			delete(hash.fields, field)
+		}
+	}
+	return nil
+}
+
 func (m *Memory) CanHandle(adapterName string) bool {
 	return adapterName == m.GetImplementationName()
 }
@@ -50,5 +99,12 @@ func (m *Memory) Initialize() error {
 }
 
 func NewMemory() *Memory {
-	return &Memory{mem: make(map[string]MemEntry)}
+	return &Memory{mem: make(map[string]MemEntry), hashes: make(map[string]memHash)}
+}
+
+func expiresAfter(ttl int) time.Time {
+	if ttl == 0 {
+		ttl = 86400 * 365 * 10 // 10 years should be enough
+	}
+	return time.Now().Add(time.Second * time.Duration(ttl))
 }
@@ -22,6 +22,21 @@ func TestMemory_SetGet_WithTTL(t *testing.T) {
 	assert.Equal(t, "", retrieved)
 }
 
+func TestMemory_HashFields(t *testing.T) {
+	m := store.NewMemory()
+	_, err := m.GetHash("bakery")
+	assert.Equal(t, store.ErrNotFound, err.Error())
+
+	assert.Nil(t, m.SetHashField("bakery", "rye", "bread", 0))
+	assert.Nil(t, m.SetHashField("bakery", "wheat", "roll", 0))
+	assert.Nil(t, m.SetHashField("bakery", "rye", "sourdough", 0))
+	assert.Nil(t, m.DeleteHashFields("bakery", "wheat", "non-existing"))
+
+	fields, err := m.GetHash("bakery")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"rye": "sourdough"}, fields)
+}
+
 func TestMemory_SetGet_WithoutTTL(t *testing.T) {
 	m := store.NewMemory()
 	m.Set("book", "conquest-of-bread", 0)
@@ -1,11 +1,16 @@
 package store
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const StatusCacheTtl = 86400 * 30
@@ -14,6 +19,9 @@ const StatusLongCacheTtl = 86400 * 365 * 10
 // SecretCacheTtl is used to not fetch the same `kind: Secret` multiple times during at least one Pipeline lifecycle
 const SecretCacheTtl = 120
 
+// accessTokenExpiryMargin makes sure a cached access token is not used right before it expires
+const accessTokenExpiryMargin = 5 * time.Minute
+
 type Operator struct {
 	Store
 }
@@ -44,28 +52,326 @@ func (o *Operator) RecordEventFiring(retrieved contract.PipelineInfo, eventType
 	return nil
 }
 
+// GetStatusPRCommentId returns an id of the progress comment in the Pull Request the pipeline is associated with
 func (o *Operator) GetStatusPRCommentId(pipeline contract.PipelineInfo) string {
-	return o.readOrEmpty(pipeline, "PRCommentId")
+	return o.readOrEmpty(pipeline, prScopedKey(pipeline, "PRCommentId"))
 }
 
 func (o *Operator) GetLastRecordedPipelineStatus(pipeline contract.PipelineInfo) string {
-	return o.readOrEmpty(pipeline, "PRLastStatus")
+	return o.readOrEmpty(pipeline, prScopedKey(pipeline, "PRLastStatus"))
+}
+
+// IsPRCommentUpToDate tells if the progress comment already shows the current status and stages of the Pipeline
+func (o *Operator) IsPRCommentUpToDate(pipeline contract.PipelineInfo) bool {
+	return o.GetLastRecordedPipelineStatus(pipeline) == recordedPipelineStatus(pipeline)
 }
 
 func (o *Operator) RecordInfoAboutLastComment(pipeline contract.PipelineInfo, commentId string) {
-	_ = o.Set(pipeline.GetId()+"/PRCommentId", commentId, StatusCacheTtl)
-	_ = o.Set(pipeline.GetId()+"/PRLastStatus", string(pipeline.GetStatus())+"/"+pipeline.ToHash(), StatusCacheTtl)
+	_ = o.Set(pipeline.GetId()+"/"+prScopedKey(pipeline, "PRCommentId"), commentId, StatusCacheTtl)
+	_ = o.Set(pipeline.GetId()+"/"+prScopedKey(pipeline, "PRLastStatus"), recordedPipelineStatus(pipeline), StatusCacheTtl)
+}
+
+// recordedPipelineStatus includes the stages, as the comment shows them too
+func recordedPipelineStatus(pipeline contract.PipelineInfo) string {
+	return string(pipeline.GetStatus()) + "/" + pipeline.ToHash()
 }
 
 func (o *Operator) RecordSummaryCommentCreated(pipeline contract.PipelineInfo) {
-	_ = o.Set(pipeline.GetId()+"/PRSummaryCreated", "true", StatusLongCacheTtl)
+	_ = o.Set(pipeline.GetId()+"/"+prScopedKey(pipeline, "PRSummaryCreated"), "true", StatusLongCacheTtl)
 }
 
 func (o *Operator) WasSummaryCommentCreated(pipeline contract.PipelineInfo) bool {
-	value, _ := o.Get(pipeline.GetId() + "/PRSummaryCreated")
+	value, _ := o.Get(pipeline.GetId() + "/" + prScopedKey(pipeline, "PRSummaryCreated"))
+	return value == "true"
+}
+
+// GetDeploymentId returns an id of the SCM deployment created for the Pipeline run
+func (o *Operator) GetDeploymentId(pipeline contract.PipelineInfo) string {
+	return o.readOrEmpty(pipeline, "DeploymentId")
+}
+
+// GetDeploymentState returns the deployment state last sent to the SCM
+func (o *Operator) GetDeploymentState(pipeline contract.PipelineInfo) string {
+	return o.readOrEmpty(pipeline, "DeploymentState")
+}
+
+// RecordDeploymentState keeps the id of the created deployment and its last sent state.
+// An empty state means, that the deployment was created, but its state was not sent
+func (o *Operator) RecordDeploymentState(pipeline contract.PipelineInfo, deploymentId string, state string) {
+	_ = o.Set(pipeline.GetId()+"/DeploymentId", deploymentId, StatusCacheTtl)
+	_ = o.Set(pipeline.GetId()+"/DeploymentState", state, StatusCacheTtl)
+}
+
+// WasCommentMinimized tells if the comment was already minimized (hidden) as superseded
+func (o *Operator) WasCommentMinimized(repoUrl string, commentId string) bool {
+	value, _ := o.Get("MinimizedComment/" + repoUrl + "/" + commentId)
+	return value == "true"
+}
+
+func (o *Operator) RecordCommentMinimized(repoUrl string, commentId string) {
+	_ = o.Set("MinimizedComment/"+repoUrl+"/"+commentId, "true", StatusCacheTtl)
+}
+
+// RecordPipelineRunSuperseded marks a run (by its Pipeline id), that its comments in the Pull Request were superseded by a newer run
+func (o *Operator) RecordPipelineRunSuperseded(pipelineId string, prId string) {
+	_ = o.Set(pipelineId+"/SupersededInPR/"+prId, "true", StatusCacheTtl)
+}
+
+// IsPipelineRunSuperseded tells if a newer run of the Pipeline has superseded comments of this run in the Pull Request
+func (o *Operator) IsPipelineRunSuperseded(pipeline contract.PipelineInfo) bool {
+	value, _ := o.Get(pipeline.GetId() + "/SupersededInPR/" + pipeline.GetSCMContext().PrId)
 	return value == "true"
 }
 
+// GetLastRecordedStageStatus returns a status of the stage, that was last sent to the SCM as a commit status
+func (o *Operator) GetLastRecordedStageStatus(pipeline contract.PipelineInfo, stage string) string {
+	return o.readOrEmpty(pipeline, "StageStatus/"+stage)
+}
+
+func (o *Operator) RecordStageStatus(pipeline contract.PipelineInfo, stage string, status contract.Status) {
+	_ = o.Set(pipeline.GetId()+"/StageStatus/"+stage, string(status), StatusCacheTtl)
+}
+
+// CommentSection is a part of a Pull Request comment shared by all Pipelines of the Pull Request
+type CommentSection struct {
+	Pipeline string `json:"pipeline"`
+	Content  string `json:"content"`
+	// Commit is the commit the Pipeline was running for
+	Commit string `json:"commit"`
+	// Started tells when the Pipeline was started, it decides which commit is the latest one
+	Started time.Time `json:"started"`
+}
+
+// GetSharedCommentSections returns sections of the Pull Request comment shared by all Pipelines, keyed by Pipeline name
+func (o *Operator) GetSharedCommentSections(repoUrl string, prId string) map[string]CommentSection {
+	fields, _ := o.getHash("SharedComment/" + repoUrl + "/" + prId + "/SectionsByPipeline")
+	return decodeSections(fields)
+}
+
+// RecordSharedCommentSection stores the section in the sections of the Pull Request, replacing the previous section
+// of the same Pipeline (e.g. from a previous run). Each section is a separate field of a hash, so Pipelines updated
+// at the same time by multiple replicas do not overwrite each other's sections.
+// Only sections of the latest commit are kept, see setFieldAtLatestCommit. Returns all sections after the merge
+func (o *Operator) RecordSharedCommentSection(repoUrl string, prId string, section CommentSection) map[string]CommentSection {
+	encoded, _ := json.Marshal(section)
+	fields := o.setFieldAtLatestCommit("SharedComment/"+repoUrl+"/"+prId+"/SectionsByPipeline", section.Pipeline,
+		string(encoded), section.Commit, section.Started)
+	return decodeSections(fields)
+}
+
+func decodeSections(fields map[string]string) map[string]CommentSection {
+	sections := make(map[string]CommentSection)
+	for pipeline, encoded := range fields {
+		section := CommentSection{}
+		if pipeline != latestCommitField && json.Unmarshal([]byte(encoded), &section) == nil {
+			sections[pipeline] = section
+		}
+	}
+	return sections
+}
+
+// GetSharedCommentId returns an id of the Pull Request comment shared by all Pipelines of the Pull Request
+func (o *Operator) GetSharedCommentId(repoUrl string, prId string) string {
+	existing, _ := o.Get("SharedComment/" + repoUrl + "/" + prId + "/Id")
+	return existing
+}
+
+// RecordSharedComment keeps the id of the shared comment together with a checksum of its content
+func (o *Operator) RecordSharedComment(repoUrl string, prId string, commentId string, content string) {
+	_ = o.Set("SharedComment/"+repoUrl+"/"+prId+"/Id", commentId, StatusCacheTtl)
+	_ = o.Set("SharedComment/"+repoUrl+"/"+prId+"/Hash", checksum(content), StatusCacheTtl)
+}
+
+// IsSharedCommentUpToDate tells if the shared comment already has the given content, so there is no need to edit it
+func (o *Operator) IsSharedCommentUpToDate(repoUrl string, prId string, content string) bool {
+	existing, _ := o.Get("SharedComment/" + repoUrl + "/" + prId + "/Hash")
+	return existing == checksum(content)
+}
+
+// pipelineStatusAtCommit is a status of a Pipeline, that ran for the commit
+type pipelineStatusAtCommit struct {
+	Status contract.Status `json:"status"`
+	Commit string          `json:"commit"`
+}
+
+// RecordPullRequestPipelineStatus keeps the last status of each Pipeline of the Pull Request, keyed by Pipeline name,
+// so a rerun replaces the status of the previous run. Only statuses of the latest commit are kept, see setFieldAtLatestCommit,
+// so e.g. a failure before a push does not count anymore. Returns statuses of all Pipelines after the merge
+func (o *Operator) RecordPullRequestPipelineStatus(repoUrl string, prId string, pipeline string, commit string, started time.Time,
+	status contract.Status) map[string]contract.Status {
+
+	encoded, _ := json.Marshal(pipelineStatusAtCommit{Status: status, Commit: commit})
+	fields := o.setFieldAtLatestCommit("PullRequestStatusesByPipeline/"+repoUrl+"/"+prId, pipeline, string(encoded), commit, started)
+
+	statuses := make(map[string]contract.Status)
+	for name, value := range fields {
+		existing := pipelineStatusAtCommit{}
+		if json.Unmarshal([]byte(value), &existing) == nil {
+			statuses[name] = existing.Status
+		}
+	}
+	return statuses
+}
+
+// GetPullRequestLabel returns the status label last applied to the Pull Request
+func (o *Operator) GetPullRequestLabel(repoUrl string, prId string) string {
+	existing, _ := o.Get("PullRequestLabel/" + repoUrl + "/" + prId)
+	return existing
+}
+
+func (o *Operator) RecordPullRequestLabel(repoUrl string, prId string, label string) {
+	_ = o.Set("PullRequestLabel/"+repoUrl+"/"+prId, label, StatusCacheTtl)
+}
+
+// GetDiscoveredPullRequests returns Pull Request ids found for a commit. Second value tells if the lookup was already done
+func (o *Operator) GetDiscoveredPullRequests(repoUrl string, commit string) ([]string, bool) {
+	existing, err := o.Get("DiscoveredPullRequests/" + repoUrl + "/" + commit)
+	if err != nil {
+		return []string{}, false
+	}
+	if existing == "" {
+		return []string{}, true
+	}
+	return strings.Split(existing, ","), true
+}
+
+// RecordDiscoveredPullRequests keeps Pull Request ids found for a commit. Empty results should be kept shorter,
+// as the Pull Request could be created after the Pipeline was started
+func (o *Operator) RecordDiscoveredPullRequests(repoUrl string, commit string, prIds []string, ttl int) {
+	_ = o.Set("DiscoveredPullRequests/"+repoUrl+"/"+commit, strings.Join(prIds, ","), ttl)
+}
+
+// GetMessageReference returns an identifier of a message posted by a receiver (e.g. Slack channel and message timestamp)
+func (o *Operator) GetMessageReference(pipeline contract.PipelineInfo, receiver string) string {
+	return o.readOrEmpty(pipeline, receiver+"/MessageRef")
+}
+
+// RecordMessageReference keeps the identifier of a posted message together with a checksum of its content
+func (o *Operator) RecordMessageReference(pipeline contract.PipelineInfo, receiver string, reference string, content string) {
+	_ = o.Set(pipeline.GetId()+"/"+receiver+"/MessageRef", reference, StatusCacheTtl)
+	_ = o.Set(pipeline.GetId()+"/"+receiver+"/MessageHash", checksum(content), StatusCacheTtl)
+}
+
+// IsMessageUpToDate tells if the posted message already has the given content, so there is no need to edit it
+func (o *Operator) IsMessageUpToDate(pipeline contract.PipelineInfo, receiver string, content string) bool {
+	return o.readOrEmpty(pipeline, receiver+"/MessageHash") == checksum(content)
+}
+
+// latestCommitField is a hash field keeping the latest commit of a Pull Request, Pipeline names cannot contain "@"
+const latestCommitField = "@latestCommit"
+
+// latestCommit is the commit of the Pull Request, for which the latest Pipeline was started
+type latestCommit struct {
+	Commit  string    `json:"commit"`
+	Started time.Time `json:"started"`
+}
+
+// setFieldAtLatestCommit sets a JSON value with a "commit" key in a hash of Pull Request Pipelines, where only
+// values of the latest commit are kept. A Pipeline started later for another commit makes its commit the latest one
+// and drops values of other commits. A value of a Pipeline started before the latest commit arrived (e.g. reconciled
+// late) is rejected, so it cannot drop values of the newer commit. Returns all values of the latest commit
+func (o *Operator) setFieldAtLatestCommit(key string, field string, value string, commit string, started time.Time) map[string]string {
+	fields, _ := o.getHash(key)
+	latest := latestCommit{}
+	_ = json.Unmarshal([]byte(fields[latestCommitField]), &latest)
+
+	if latest.Commit != commit {
+		if latest.Commit != "" && !started.IsZero() && started.Before(latest.Started) {
+			return fieldsOfCommit(fields, latest.Commit)
+		}
+		latest = latestCommit{Commit: commit, Started: started}
+		encoded, _ := json.Marshal(latest)
+		_ = o.setHashField(key, latestCommitField, string(encoded), StatusCacheTtl)
+	}
+	_ = o.setHashField(key, field, value, StatusCacheTtl)
+	fields[field] = value
+
+	outdated := make([]string, 0)
+	for name := range fields {
+		if name != latestCommitField && !isValueOfCommit(fields[name], commit) {
+			outdated = append(outdated, name)
+		}
+	}
+	if len(outdated) > 0 {
+		_ = o.deleteHashFields(key, outdated...)
+	}
+	return fieldsOfCommit(fields, commit)
+}
+
+func fieldsOfCommit(fields map[string]string, commit string) map[string]string {
+	result := make(map[string]string)
+	for name, value := range fields {
+		if name != latestCommitField && isValueOfCommit(value, commit) {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+func isValueOfCommit(value string, commit string) bool {
+	decoded := struct {
+		Commit string `json:"commit"`
+	}{}
+	return json.Unmarshal([]byte(value), &decoded) == nil && decoded.Commit == commit
+}
+
+// setHashField uses the HashStore, when the Store implements it. Otherwise the whole hash is kept serialized
+// under the key, which is not atomic
+func (o *Operator) setHashField(key string, field string, value string, ttl int) error {
+	if hashes, ok := o.Store.(HashStore); ok {
+		return hashes.SetHashField(key, field, value, ttl)
+	}
+	fields, _ := o.getHash(key)
+	fields[field] = value
+	return o.setSerializedHash(key, fields, ttl)
+}
+
+func (o *Operator) getHash(key string) (map[string]string, error) {
+	if hashes, ok := o.Store.(HashStore); ok {
+		fields, err := hashes.GetHash(key)
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		return fields, err
+	}
+	fields := make(map[string]string)
+	encoded, err := o.Get(key)
+	if err != nil {
+		return fields, err
+	}
+	if decodeErr := json.Unmarshal([]byte(encoded), &fields); decodeErr != nil {
+		return make(map[string]string), errors.Wrap(decodeErr, "cannot decode hash")
+	}
+	return fields, nil
+}
+
+func (o *Operator) deleteHashFields(key string, fields ...string) error {
+	if hashes, ok := o.Store.(HashStore); ok {
+		return hashes.DeleteHashFields(key, fields...)
+	}
+	existing, err := o.getHash(key)
+	if err != nil {
+		return nil
+	}
+	for _, field := range fields {
+		delete(existing, field)
+	}
+	return o.setSerializedHash(key, existing, StatusCacheTtl)
+}
+
+func (o *Operator) setSerializedHash(key string, fields map[string]string, ttl int) error {
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode hash")
+	}
+	return o.Set(key, string(encoded), ttl)
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 func (o *Operator) readOrEmpty(pipeline contract.PipelineInfo, key string) string {
 	ident := pipeline.GetId() + "/" + key
 	existing, err := o.Get(ident)
@@ -86,6 +392,24 @@ func (o *Operator) PushConfigSecretKey(namespace string, refKey string, refSecre
 	_ = o.Set(ident, val, SecretCacheTtl)
 }
 
+// GetCachedJobContext returns a JobContext, that was previously enriched e.g. with the data fetched from the SCM API
+func (o *Operator) GetCachedJobContext(key string) (contract.JobContext, bool) {
+	existing, err := o.Get("JobContext/" + key)
+	if err != nil || existing == "" {
+		return contract.JobContext{}, false
+	}
+	ctx := contract.JobContext{}
+	if jsonErr := json.Unmarshal([]byte(existing), &ctx); jsonErr != nil {
+		return contract.JobContext{}, false
+	}
+	return ctx, true
+}
+
+func (o *Operator) RecordJobContext(key string, ctx contract.JobContext) {
+	encoded, _ := json.Marshal(ctx)
+	_ = o.Set("JobContext/"+key, string(encoded), StatusCacheTtl)
+}
+
 func (o *Operator) WasPipelineProcessedAtThisState(pipeline contract.PipelineInfo) bool {
 	ident := pipeline.GetId() + "/LastProcessingStateHash"
 	lastStateHash, err := o.Get(ident)
@@ -100,6 +424,15 @@ func (o *Operator) RecordPipelineStateProcessed(pipeline contract.PipelineInfo)
 	_ = o.Set(ident, pipeline.ToHash(), StatusCacheTtl)
 }
 
+// prScopedKey makes the key unique per discovered Pull Request, as then a single Pipeline may report to multiple Pull Requests.
+// Annotated Pull Request keeps the original key, so the records are not lost on upgrade
+func prScopedKey(pipeline contract.PipelineInfo, key string) string {
+	if !pipeline.GetSCMContext().PrDiscovered {
+		return key
+	}
+	return "pr-" + pipeline.GetSCMContext().PrId + "/" + key
+}
+
 func (o *Operator) count(pipeline contract.PipelineInfo, key string) int {
 	ident := pipeline.GetId() + "/" + key
 	existing, err := o.Get(ident)
@@ -128,3 +461,29 @@ func (o *Operator) HowManyTimesErrored(pipeline contract.PipelineInfo) int {
 	count, _ := strconv.Atoi(existing)
 	return count
 }
+
+// GetAccessToken returns a cached access token (e.g. GitHub App installation token), empty when expired or missing
+func (o *Operator) GetAccessToken(key string) string {
+	existing, _ := o.Get("AccessToken/" + key)
+	return existing
+}
+
+// RecordAccessToken keeps the access token until it expires. The token is forgotten a few minutes earlier,
+// so it does not expire in the middle of a reconciliation
+func (o *Operator) RecordAccessToken(key string, token string, expiresAt time.Time) {
+	ttl := int(time.Until(expiresAt.Add(-accessTokenExpiryMargin)).Seconds())
+	if ttl <= 0 {
+		return
+	}
+	_ = o.Set("AccessToken/"+key, token, ttl)
+}
+
+// GetAppInstallationId returns a cached id of an App installation (e.g. GitHub App) for a repository owner
+func (o *Operator) GetAppInstallationId(app string, owner string) string {
+	existing, _ := o.Get("AppInstallation/" + app + "/" + owner)
+	return existing
+}
+
+func (o *Operator) RecordAppInstallationId(app string, owner string, installationId string) {
+	_ = o.Set("AppInstallation/"+app+"/"+owner, installationId, StatusCacheTtl)
+}
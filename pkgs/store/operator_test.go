@@ -83,3 +83,229 @@ func TestOperator_RecordEventFiring_WasEventAlreadySent(t *testing.T) {
 	_ = o.RecordEventFiring(*createBreadBookPipeline(), "start")
 	assert.True(t, o.WasEventAlreadySent(*createBreadBookPipeline(), "start"))
 }
+
+func TestOperator_RecordDiscoveredPullRequests(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+
+	_, wasLookedUp := o.GetDiscoveredPullRequests("https://gitlab.com/aaa/bbb.git", "76ea7c7")
+	assert.False(t, wasLookedUp)
+
+	o.RecordDiscoveredPullRequests("https://gitlab.com/aaa/bbb.git", "76ea7c7", []string{}, 60)
+	prIds, wasLookedUp := o.GetDiscoveredPullRequests("https://gitlab.com/aaa/bbb.git", "76ea7c7")
+	assert.True(t, wasLookedUp, "Empty result should be also cached")
+	assert.Empty(t, prIds)
+
+	o.RecordDiscoveredPullRequests("https://gitlab.com/aaa/bbb.git", "76ea7c7", []string{"1", "4"}, 60)
+	prIds, _ = o.GetDiscoveredPullRequests("https://gitlab.com/aaa/bbb.git", "76ea7c7")
+	assert.Equal(t, []string{"1", "4"}, prIds)
+}
+
+func TestOperator_MessageReference(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	pipeline := createBreadBookPipeline()
+
+	assert.Equal(t, "", o.GetMessageReference(*pipeline, "slack"))
+	assert.False(t, o.IsMessageUpToDate(*pipeline, "slack", "baking"))
+
+	o.RecordMessageReference(*pipeline, "slack", "C123/1503435956.000247", "baking")
+
+	assert.Equal(t, "C123/1503435956.000247", o.GetMessageReference(*pipeline, "slack"))
+	assert.Equal(t, "", o.GetMessageReference(*pipeline, "msteams"))
+	assert.True(t, o.IsMessageUpToDate(*pipeline, "slack", "baking"))
+	assert.False(t, o.IsMessageUpToDate(*pipeline, "slack", "baked"))
+}
+
+func TestOperator_RecordStageStatus(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	pipeline := createBreadBookPipeline()
+
+	assert.Equal(t, "", o.GetLastRecordedStageStatus(*pipeline, "knead"))
+
+	o.RecordStageStatus(*pipeline, "knead", contract.PipelineRunning)
+
+	assert.Equal(t, string(contract.PipelineRunning), o.GetLastRecordedStageStatus(*pipeline, "knead"))
+	assert.Equal(t, "", o.GetLastRecordedStageStatus(*pipeline, "bake"))
+}
+
+func TestOperator_RecordSharedCommentSection_ReplacesSectionOfSamePipeline(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	repoUrl := "https://gitlab.com/aaa/bbb.git"
+
+	assert.Empty(t, o.GetSharedCommentSections(repoUrl, "4"))
+
+	o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/knead", Content: "kneading"})
+	o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/bake", Content: "baking"})
+	sections := o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/knead", Content: "kneaded"})
+
+	assert.Len(t, sections, 2)
+	assert.Equal(t, "kneaded", sections["default/knead"].Content)
+	assert.Equal(t, sections, o.GetSharedCommentSections(repoUrl, "4"))
+	assert.Empty(t, o.GetSharedCommentSections(repoUrl, "5"), "Sections are kept per Pull Request")
+}
+
+func TestOperator_RecordPullRequestPipelineStatus_DropsStatusesOfOtherCommits(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	repoUrl := "https://gitlab.com/aaa/bbb.git"
+	pushed := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/knead", "76ea7c7", pushed, contract.PipelineFailed)
+	statuses := o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/bake", "76ea7c7", pushed, contract.PipelineRunning)
+	assert.Equal(t, map[string]contract.Status{"default/knead": contract.PipelineFailed, "default/bake": contract.PipelineRunning}, statuses)
+
+	// a new commit was pushed, the failure of "default/knead" is not relevant anymore
+	statuses = o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/bake", "161bbb2", pushed.Add(time.Hour), contract.PipelineSucceeded)
+	assert.Equal(t, map[string]contract.Status{"default/bake": contract.PipelineSucceeded}, statuses)
+	assert.Equal(t, statuses, o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/bake", "161bbb2", pushed.Add(time.Hour), contract.PipelineSucceeded))
+}
+
+func TestOperator_RecordPullRequestPipelineStatus_RejectsStatusOfOlderCommit(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	repoUrl := "https://gitlab.com/aaa/bbb.git"
+	pushed := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/knead", "161bbb2", pushed.Add(time.Hour), contract.PipelineRunning)
+
+	// "default/bake" of the previous commit failed and is reconciled late, it must not count for the newer commit
+	statuses := o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/bake", "76ea7c7", pushed, contract.PipelineFailed)
+	assert.Equal(t, map[string]contract.Status{"default/knead": contract.PipelineRunning}, statuses)
+
+	statuses = o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/knead", "161bbb2", pushed.Add(time.Hour), contract.PipelineSucceeded)
+	assert.Equal(t, map[string]contract.Status{"default/knead": contract.PipelineSucceeded}, statuses)
+}
+
+func TestOperator_RecordSharedCommentSection_DropsSectionsOfOtherCommits(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	repoUrl := "https://gitlab.com/aaa/bbb.git"
+	pushed := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/knead", Content: "kneaded", Commit: "76ea7c7", Started: pushed})
+	o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/bake", Content: "baked", Commit: "76ea7c7", Started: pushed})
+
+	// a new commit was pushed, the Pipeline "default/bake" did not start yet for it
+	sections := o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/knead", Content: "kneading",
+		Commit: "161bbb2", Started: pushed.Add(time.Hour)})
+
+	assert.Equal(t, map[string]store.CommentSection{
+		"default/knead": {Pipeline: "default/knead", Content: "kneading", Commit: "161bbb2", Started: pushed.Add(time.Hour)},
+	}, sections)
+	assert.Equal(t, sections, o.GetSharedCommentSections(repoUrl, "4"))
+}
+
+func TestOperator_RecordSharedCommentSection_RejectsSectionOfOlderCommit(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	repoUrl := "https://gitlab.com/aaa/bbb.git"
+	pushed := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/knead", Content: "kneading",
+		Commit: "161bbb2", Started: pushed.Add(time.Hour)})
+
+	// "default/bake" of the previous commit is reconciled late, it must not drop the section of the newer commit
+	sections := o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/bake", Content: "baked",
+		Commit: "76ea7c7", Started: pushed})
+
+	assert.Equal(t, map[string]store.CommentSection{
+		"default/knead": {Pipeline: "default/knead", Content: "kneading", Commit: "161bbb2", Started: pushed.Add(time.Hour)},
+	}, sections)
+	assert.Equal(t, sections, o.GetSharedCommentSections(repoUrl, "4"))
+}
+
+// keyValueStore is a Store without HashStore support, like Stores implemented outside of this repository
+type keyValueStore struct {
+	store.Store
+}
+
+func TestOperator_RecordSharedCommentSection_WorksWithStoreWithoutHashes(t *testing.T) {
+	o := store.Operator{Store: keyValueStore{store.NewMemory()}}
+	repoUrl := "https://gitlab.com/aaa/bbb.git"
+	_, isHashStore := o.Store.(store.HashStore)
+	assert.False(t, isHashStore)
+
+	o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/knead", Content: "kneaded", Commit: "76ea7c7"})
+	sections := o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/bake", Content: "baked", Commit: "76ea7c7"})
+
+	assert.Len(t, sections, 2)
+	assert.Equal(t, sections, o.GetSharedCommentSections(repoUrl, "4"))
+
+	sections = o.RecordSharedCommentSection(repoUrl, "4", store.CommentSection{Pipeline: "default/bake", Content: "baking", Commit: "161bbb2"})
+	assert.Equal(t, map[string]store.CommentSection{
+		"default/bake": {Pipeline: "default/bake", Content: "baking", Commit: "161bbb2"},
+	}, sections)
+}
+
+func TestOperator_RecordSharedComment(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	repoUrl := "https://gitlab.com/aaa/bbb.git"
+
+	o.RecordSharedComment(repoUrl, "4", "1001", "baking")
+
+	assert.Equal(t, "1001", o.GetSharedCommentId(repoUrl, "4"))
+	assert.True(t, o.IsSharedCommentUpToDate(repoUrl, "4", "baking"))
+	assert.False(t, o.IsSharedCommentUpToDate(repoUrl, "4", "baked"))
+	assert.Equal(t, "", o.GetSharedCommentId(repoUrl, "5"))
+}
+
+func TestOperator_IsPRCommentUpToDate(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	scm, _ := contract.NewSCMContext("https://gitlab.com/aaa/bbb.git")
+	scm.PrId = "4"
+	createPipeline := func(status contract.Status) contract.PipelineInfo {
+		return *contract.NewPipelineInfo(scm, "default", "hello-kropotkin", "the-conquest-of-bread", time.Now(),
+			[]contract.PipelineStage{{Name: "bake", Status: status}}, labels.Set{}, labels.Set{}, &config.Data{})
+	}
+
+	assert.False(t, o.IsPRCommentUpToDate(createPipeline(contract.PipelineRunning)))
+
+	o.RecordInfoAboutLastComment(createPipeline(contract.PipelineRunning), "1001")
+
+	assert.True(t, o.IsPRCommentUpToDate(createPipeline(contract.PipelineRunning)))
+	assert.False(t, o.IsPRCommentUpToDate(createPipeline(contract.PipelineSucceeded)))
+	assert.Equal(t, "1001", o.GetStatusPRCommentId(createPipeline(contract.PipelineSucceeded)))
+}
+
+func TestOperator_GetStatusPRCommentId_KeepsKeyOfAnnotatedPullRequest(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	annotated := *createBreadBookPipeline()
+	scm := annotated.GetSCMContext()
+	scm.PrId = "4"
+	annotated = annotated.WithSCMContext(scm)
+	scm.PrDiscovered = true
+	discovered := annotated.WithSCMContext(scm)
+
+	// recorded by a previous version of the controller
+	_ = o.Set(annotated.GetId()+"/PRCommentId", "1001", store.StatusCacheTtl)
+
+	assert.Equal(t, "1001", o.GetStatusPRCommentId(annotated))
+	assert.Equal(t, "", o.GetStatusPRCommentId(discovered))
+
+	o.RecordInfoAboutLastComment(discovered, "1002")
+	assert.Equal(t, "1001", o.GetStatusPRCommentId(annotated))
+	assert.Equal(t, "1002", o.GetStatusPRCommentId(discovered))
+}
+
+func TestOperator_RecordDeploymentState(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	pipeline := createBreadBookPipeline()
+	assert.Equal(t, "", o.GetDeploymentId(*pipeline))
+
+	o.RecordDeploymentState(*pipeline, "7", "")
+	assert.Equal(t, "7", o.GetDeploymentId(*pipeline))
+	assert.Equal(t, "", o.GetDeploymentState(*pipeline), "the deployment was created, but its state was not sent")
+
+	o.RecordDeploymentState(*pipeline, "7", "in_progress")
+	assert.Equal(t, "in_progress", o.GetDeploymentState(*pipeline))
+}
+
+func TestOperator_RecordPullRequestPipelineStatus(t *testing.T) {
+	o := store.Operator{Store: store.NewMemory()}
+	repoUrl := "https://gitlab.com/aaa/bbb.git"
+	pushed := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/knead", "76ea7c7", pushed, contract.PipelineFailed)
+	statuses := o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/bake", "76ea7c7", pushed, contract.PipelineRunning)
+	assert.Equal(t, map[string]contract.Status{"default/knead": contract.PipelineFailed, "default/bake": contract.PipelineRunning}, statuses)
+
+	// rerun replaces the status of the previous run
+	statuses = o.RecordPullRequestPipelineStatus(repoUrl, "4", "default/knead", "76ea7c7", pushed, contract.PipelineSucceeded)
+	assert.Equal(t, contract.PipelineSucceeded, statuses["default/knead"])
+	assert.Len(t, o.RecordPullRequestPipelineStatus(repoUrl, "5", "default/knead", "76ea7c7", pushed, contract.PipelineRunning), 1)
+}
@@ -29,6 +29,37 @@ func (r *Redis) Get(key string) (string, error) {
 	return fetch.Result()
 }
 
+// SetHashField sets the field and refreshes the ttl in a single transaction
+func (r *Redis) SetHashField(key string, field string, value string, ttl int) error {
+	if ttl == 0 {
+		ttl = 86400 * 365 * 10 // 10 years should be enough
+	}
+	_, err := r.client.TxPipelined(context.TODO(), func(pipe redis.Pipeliner) error {
+		pipe.HSet(context.TODO(), key, field, value)
+		pipe.Expire(context.TODO(), key, time.Second*time.Duration(ttl))
+		return nil
+	})
+	return err
+}
+
+func (r *Redis) GetHash(key string) (map[string]string, error) {
+	fields, err := r.client.HGetAll(context.TODO(), key).Result()
+	if err != nil {
+		return map[string]string{}, err
+	}
+	if len(fields) == 0 {
+		return fields, errors.New(ErrNotFound)
+	}
+	return fields, nil
+}
+
+func (r *Redis) DeleteHashFields(key string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return r.client.HDel(context.TODO(), key, fields...).Err()
+}
+
 func (r *Redis) CanHandle(adapterName string) bool {
 	return adapterName == r.GetImplementationName()
 }
@@ -46,4 +46,12 @@ func TestRedis_Flow(t *testing.T) {
 	get, getErr := adapter.Get("Hello/World")
 	assert.Equal(t, "Bread", get)
 	assert.Nil(t, getErr)
+
+	// CASE: Hash fields are set one by one, other fields are kept
+	assert.Nil(t, adapter.SetHashField("Hello/Hash", "rye", "Bread", 100))
+	assert.Nil(t, adapter.SetHashField("Hello/Hash", "wheat", "Roll", 100))
+	assert.Nil(t, adapter.DeleteHashFields("Hello/Hash", "wheat"))
+	hash, hashErr := adapter.GetHash("Hello/Hash")
+	assert.Equal(t, map[string]string{"rye": "Bread"}, hash)
+	assert.Nil(t, hashErr)
 }
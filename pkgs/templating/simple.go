@@ -2,6 +2,10 @@ package templating
 
 import (
 	"bytes"
+	"encoding/json"
+	htmlTemplate "html/template"
+	"strings"
+
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
 	"github.com/pkg/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,8 +38,65 @@ func TemplateSummaryComment(templateStr string, pipeline contract.PipelineInfo,
 	})
 }
 
+// TemplateCommitStatus renders a part of the commit status (context, description). `.stage` is nil for the Pipeline-wide status
+func TemplateCommitStatus(templateStr string, part string, pipeline contract.PipelineInfo, stage *contract.PipelineStage) (string, error) {
+	result, err := render(templateStr, "commit-status-"+part+"-template", map[string]interface{}{
+		"pipeline": pipeline,
+		"stage":    stage,
+	})
+	return strings.TrimSpace(result), err
+}
+
+// TemplateWebhookPart renders a part of the HTTP request (url, headers, body) sent by the webhook receiver
+func TemplateWebhookPart(templateStr string, part string, pipeline contract.PipelineInfo, event string, payload interface{}) (string, error) {
+	return render(templateStr, "webhook-"+part+"-template", map[string]interface{}{
+		"pipeline": pipeline,
+		"event":    event,
+		"payload":  payload,
+	})
+}
+
+// TemplateChatMessage renders a chat message (e.g. Slack Block Kit, Adaptive Card). Receiver-specific helper variables
+// are available next to the `.pipeline`
+func TemplateChatMessage(templateStr string, name string, pipeline contract.PipelineInfo, variables map[string]interface{}) (string, error) {
+	merged := map[string]interface{}{"pipeline": pipeline}
+	for key, value := range variables {
+		merged[key] = value
+	}
+	return render(templateStr, name, merged)
+}
+
+// TemplateHtml renders an HTML document (e.g. e-mail body). Values are escaped according to the context they are placed in
+func TemplateHtml(templateStr string, name string, pipeline contract.PipelineInfo, variables map[string]interface{}) (string, error) {
+	merged := map[string]interface{}{"pipeline": pipeline}
+	for key, value := range variables {
+		merged[key] = value
+	}
+	t, err := htmlTemplate.New(name).Funcs(htmlTemplate.FuncMap(functions)).Parse(templateStr)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot create a "+name+". Please check your template")
+	}
+	var result bytes.Buffer
+	if execErr := t.Execute(&result, merged); execErr != nil {
+		return "", errors.Wrap(execErr, "cannot create a "+name+". Please check your template")
+	}
+	return result.String(), nil
+}
+
+var functions = template.FuncMap{
+	"toJson": toJson,
+}
+
+func toJson(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot encode value as JSON")
+	}
+	return string(encoded), nil
+}
+
 func render(templateStr string, name string, variables map[string]interface{}) (string, error) {
-	t, err := template.New(name).Parse(templateStr)
+	t, err := template.New(name).Funcs(functions).Parse(templateStr)
 	if err != nil {
 		return "", errors.Wrap(err, "cannot create a "+name+". Please check your template")
 	}
@@ -1,6 +1,7 @@
 package templating_test
 
 import (
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/contract"
 	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/batch/v1"
@@ -20,3 +21,21 @@ func TestTemplateDashboardUrl(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "https://console-openshift-console.apps.my-cluster.org/k8s/ns/default/tekton.dev~v1beta1~PipelineRun/peppa", result)
 }
+
+func TestTemplateWebhookPart_ToJson(t *testing.T) {
+	result, err := templating.TemplateWebhookPart(`{"event": {{ .event | toJson }}, "data": {{ .payload | toJson }}}`, "body",
+		contract.PipelineInfo{}, "finished", map[string]string{"quote": `"bread"`})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `{"event": "finished", "data": {"quote":"\"bread\""}}`, result)
+}
+
+func TestTemplateCommitStatus(t *testing.T) {
+	stage := contract.PipelineStage{Name: "bake", Status: contract.PipelineRunning}
+
+	pipelineWide, _ := templating.TemplateCommitStatus(` {{ if .stage }}stage{{ else }}pipeline{{ end }} `, "context", contract.PipelineInfo{}, nil)
+	perStage, _ := templating.TemplateCommitStatus(`ci/{{ .stage.Name }}`, "context", contract.PipelineInfo{}, &stage)
+
+	assert.Equal(t, "pipeline", pipelineWide)
+	assert.Equal(t, "ci/bake", perStage)
+}
@@ -0,0 +1,16 @@
+package templating
+
+import "unicode/utf8"
+
+// Tail returns last maxLength bytes of the text, prefixed with "..." when it was cut.
+// The cut is moved forward to a rune boundary, so multibyte characters are not split
+func Tail(text string, maxLength int) string {
+	if maxLength <= 0 || len(text) <= maxLength {
+		return text
+	}
+	start := len(text) - maxLength
+	for start < len(text) && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	return "..." + text[start:]
+}
@@ -0,0 +1,19 @@
+package templating_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kube-cicd/pipelines-feedback-core/pkgs/templating"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTail(t *testing.T) {
+	assert.Equal(t, "Bread", templating.Tail("Bread", 5))
+	assert.Equal(t, "Bread", templating.Tail("Bread", 0), "no limit")
+	assert.Equal(t, "...ead", templating.Tail("Bread", 3))
+
+	cut := templating.Tail("Chleb żółty", 5)
+	assert.True(t, utf8.ValidString(cut), "multibyte characters are not split")
+	assert.Equal(t, "...łty", cut)
+}